@@ -1,5 +1,28 @@
 import axios from "axios";
 
+let refreshing = null;
+
+// refreshSession swaps the stored refresh token for a new pair. Concurrent
+// 401s share one request, since each refresh token only works once.
+const refreshSession = () => {
+  if (!refreshing) {
+    const refresh_token = localStorage.getItem("refresh_token");
+    refreshing = axios
+      .post(`${import.meta.env.VITE_IDENTITY_URL}/auth/refresh`, {
+        refresh_token,
+      })
+      .then(({ data }) => {
+        localStorage.setItem("token", data.token);
+        localStorage.setItem("refresh_token", data.refresh_token);
+        return data.token;
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
 const createClient = (baseURL) => {
   const instance = axios.create({
     baseURL,
@@ -13,6 +36,29 @@ const createClient = (baseURL) => {
     return config;
   });
 
+  instance.interceptors.response.use(undefined, async (error) => {
+    const original = error.config;
+    if (
+      error.response?.status !== 401 ||
+      original._retried ||
+      original.url?.startsWith("/auth/") ||
+      !localStorage.getItem("refresh_token")
+    ) {
+      throw error;
+    }
+
+    original._retried = true;
+    try {
+      const token = await refreshSession();
+      original.headers.Authorization = `Bearer ${token}`;
+      return instance(original);
+    } catch {
+      localStorage.removeItem("token");
+      localStorage.removeItem("refresh_token");
+      throw error;
+    }
+  });
+
   return instance;
 };
 
@@ -26,6 +72,21 @@ export const auth = {
     identityClient.post("/auth/signup", { email, password }),
   signIn: (email, password) =>
     identityClient.post("/auth/signin", { email, password }),
+  logout: (refresh_token) =>
+    identityClient.post("/auth/logout", { refresh_token }),
+  verifyEmail: (token) =>
+    identityClient.post("/auth/verify-email", { token }),
+  forgotPassword: (email) =>
+    identityClient.post("/auth/forgot-password", { email }),
+  resetPassword: (token, password) =>
+    identityClient.post("/auth/reset-password", { token, password }),
+  verifyTwoFactor: (challenge_token, code) =>
+    identityClient.post(
+      "/auth/2fa/verify",
+      code.includes("-")
+        ? { challenge_token, recovery_code: code }
+        : { challenge_token, code },
+    ),
 };
 
 export const projects = {
@@ -64,6 +125,8 @@ export const alerts = {
   getRules: (projectId) => alertClient.get(`/projects/${projectId}/rules`),
   createRule: (projectId, rule) =>
     alertClient.post(`/projects/${projectId}/rules`, rule),
+  testExpression: (projectId, expression) =>
+    alertClient.post(`/projects/${projectId}/rules/test`, { expression }),
   deleteRule: (projectId, ruleId) =>
     alertClient.delete(`/projects/${projectId}/rules/${ruleId}`),
 };
@@ -17,8 +17,25 @@ const CONDITIONS = [
     label: "Count Threshold",
     desc: "Fires when an issue exceeds a set occurrence count",
   },
+  {
+    value: "event_frequency",
+    label: "Event Frequency",
+    desc: "Fires when an issue gets more than N events within a time window",
+  },
+  {
+    value: "distinct_issues",
+    label: "Distinct Issues",
+    desc: "Fires when more than N issues get events within a time window",
+  },
+  {
+    value: "expression",
+    label: "Expression",
+    desc: "Fires when an expression such as level == critical and count > 20 holds",
+  },
 ];
 
+const WINDOWED = ["event_frequency", "distinct_issues"];
+
 export default function AlertRules({ projectId }) {
   const [rules, setRules] = useState([]);
   const [loading, setLoading] = useState(true);
@@ -28,6 +45,11 @@ export default function AlertRules({ projectId }) {
   const [name, setName] = useState("");
   const [condition, setCondition] = useState("new_issue");
   const [threshold, setThreshold] = useState(5);
+  const [windowMinutes, setWindowMinutes] = useState(5);
+  const [cooldownMinutes, setCooldownMinutes] = useState(0);
+  const [resolveAfterMinutes, setResolveAfterMinutes] = useState(0);
+  const [expression, setExpression] = useState("");
+  const [testResult, setTestResult] = useState(null);
   const [saving, setSaving] = useState(false);
   const [formError, setFormError] = useState("");
 
@@ -54,12 +76,26 @@ export default function AlertRules({ projectId }) {
       const { data } = await alerts.createRule(projectId, {
         name,
         condition,
-        threshold: condition === "count_threshold" ? Number(threshold) : 0,
+        threshold:
+          condition === "count_threshold" || WINDOWED.includes(condition)
+            ? Number(threshold)
+            : 0,
+        window_minutes: WINDOWED.includes(condition)
+          ? Number(windowMinutes)
+          : 0,
+        cooldown_minutes: Number(cooldownMinutes),
+        resolve_after_minutes: Number(resolveAfterMinutes),
+        expression: condition === "expression" ? expression : "",
       });
       setRules([data.rule, ...rules]);
       setName("");
       setCondition("new_issue");
       setThreshold(5);
+      setWindowMinutes(5);
+      setCooldownMinutes(0);
+      setResolveAfterMinutes(0);
+      setExpression("");
+      setTestResult(null);
       setShowForm(false);
     } catch (err) {
       setFormError(err.response?.data?.error || "Failed to create rule");
@@ -68,6 +104,17 @@ export default function AlertRules({ projectId }) {
     }
   };
 
+  const handleTest = async () => {
+    setFormError("");
+    setTestResult(null);
+    try {
+      const { data } = await alerts.testExpression(projectId, expression);
+      setTestResult(data);
+    } catch (err) {
+      setFormError(err.response?.data?.error || "Failed to test expression");
+    }
+  };
+
   const handleDelete = async (ruleId) => {
     try {
       await alerts.deleteRule(projectId, ruleId);
@@ -140,7 +187,8 @@ export default function AlertRules({ projectId }) {
             </p>
           </div>
 
-          {condition === "count_threshold" && (
+          {(condition === "count_threshold" ||
+            WINDOWED.includes(condition)) && (
             <div>
               <label className="block text-sm font-medium text-gray-700 mb-1">
                 Threshold (fire when count exceeds)
@@ -156,6 +204,90 @@ export default function AlertRules({ projectId }) {
             </div>
           )}
 
+          {WINDOWED.includes(condition) && (
+            <div>
+              <label className="block text-sm font-medium text-gray-700 mb-1">
+                Window (minutes)
+              </label>
+              <input
+                type="number"
+                min={1}
+                max={1440}
+                value={windowMinutes}
+                onChange={(e) => setWindowMinutes(e.target.value)}
+                required
+                className="w-full px-3 py-2 border border-gray-300 rounded-md text-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+              />
+            </div>
+          )}
+
+          {condition === "expression" && (
+            <div>
+              <label className="block text-sm font-medium text-gray-700 mb-1">
+                Expression
+              </label>
+              <textarea
+                value={expression}
+                onChange={(e) => {
+                  setExpression(e.target.value);
+                  setTestResult(null);
+                }}
+                required
+                rows={2}
+                placeholder="level == critical and tags.environment == production and count > 20"
+                className="w-full px-3 py-2 border border-gray-300 rounded-md text-sm font-mono focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+              />
+              <div className="flex items-center justify-between mt-1">
+                <p className="text-xs text-gray-500">
+                  {testResult
+                    ? `Matches ${testResult.matched} of the last ${testResult.tested} issues`
+                    : "Fields: level, status, previous_status, count, issue_id, tags.<key>"}
+                </p>
+                <button
+                  type="button"
+                  onClick={handleTest}
+                  disabled={!expression.trim()}
+                  className="text-xs text-blue-600 hover:text-blue-500 disabled:opacity-50"
+                >
+                  Test against recent issues
+                </button>
+              </div>
+            </div>
+          )}
+
+          <div className="grid grid-cols-2 gap-3">
+            <div>
+              <label className="block text-sm font-medium text-gray-700 mb-1">
+                Re-notify every (minutes)
+              </label>
+              <input
+                type="number"
+                min={0}
+                max={10080}
+                value={cooldownMinutes}
+                onChange={(e) => setCooldownMinutes(e.target.value)}
+                className="w-full px-3 py-2 border border-gray-300 rounded-md text-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+              />
+              <p className="text-xs text-gray-500 mt-1">0 notifies once</p>
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-gray-700 mb-1">
+                Auto-resolve after (minutes)
+              </label>
+              <input
+                type="number"
+                min={0}
+                max={10080}
+                value={resolveAfterMinutes}
+                onChange={(e) => setResolveAfterMinutes(e.target.value)}
+                className="w-full px-3 py-2 border border-gray-300 rounded-md text-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+              />
+              <p className="text-xs text-gray-500 mt-1">
+                0 waits for the issue to resolve
+              </p>
+            </div>
+          </div>
+
           <button
             type="submit"
             disabled={saving}
@@ -183,6 +315,9 @@ export default function AlertRules({ projectId }) {
                   {CONDITIONS.find((c) => c.value === rule.condition)?.label}
                   {rule.condition === "count_threshold" &&
                     ` › ${rule.threshold}`}
+                  {WINDOWED.includes(rule.condition) &&
+                    ` › ${rule.threshold} in ${rule.window_minutes}m`}
+                  {rule.condition === "expression" && ` › ${rule.expression}`}
                 </p>
               </div>
               <div className="flex items-center space-x-3">
@@ -0,0 +1,75 @@
+import { useState } from "react";
+import { Link, useNavigate, useSearchParams } from "react-router-dom";
+import { auth } from "../../api/client";
+
+export default function ResetPassword() {
+  const [searchParams] = useSearchParams();
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState("");
+  const [loading, setLoading] = useState(false);
+  const navigate = useNavigate();
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError("");
+    setLoading(true);
+
+    try {
+      await auth.resetPassword(searchParams.get("token") || "", password);
+      localStorage.removeItem("token");
+      localStorage.removeItem("refresh_token");
+      navigate("/signin");
+    } catch (err) {
+      setError(err.response?.data?.error || "Password reset failed");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-gray-50">
+      <div className="max-w-md w-full space-y-8 p-8 bg-white rounded-lg shadow">
+        <div>
+          <h2 className="text-3xl font-bold text-gray-900">Atlas</h2>
+          <p className="mt-2 text-sm text-gray-600">Choose a new password</p>
+        </div>
+
+        <form onSubmit={handleSubmit} className="space-y-6">
+          {error && (
+            <div className="bg-red-50 text-red-600 p-3 rounded text-sm">
+              {error}
+            </div>
+          )}
+
+          <div>
+            <label className="block text-sm font-medium text-gray-700">
+              New password
+            </label>
+            <input
+              type="password"
+              value={password}
+              onChange={(e) => setPassword(e.target.value)}
+              required
+              minLength={8}
+              className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+            />
+          </div>
+
+          <button
+            type="submit"
+            disabled={loading}
+            className="w-full flex justify-center py-2 px-4 border border-transparent rounded-md shadow-sm text-sm font-medium text-white bg-blue-600 hover:bg-blue-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-blue-500 disabled:opacity-50"
+          >
+            {loading ? "Saving..." : "Reset password"}
+          </button>
+
+          <p className="text-center text-sm text-gray-600">
+            <Link to="/signin" className="text-blue-600 hover:text-blue-500">
+              Back to sign in
+            </Link>
+          </p>
+        </form>
+      </div>
+    </div>
+  );
+}
@@ -7,6 +7,8 @@ export default function SignIn() {
   const [password, setPassword] = useState("");
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
+  const [challenge, setChallenge] = useState("");
+  const [code, setCode] = useState("");
   const navigate = useNavigate();
 
   const handleSubmit = async (e) => {
@@ -15,11 +17,22 @@ export default function SignIn() {
     setLoading(true);
 
     try {
-      const { data } = await auth.signIn(email, password);
+      const { data } = challenge
+        ? await auth.verifyTwoFactor(challenge, code.trim())
+        : await auth.signIn(email, password);
+      if (data.two_factor_required) {
+        setChallenge(data.challenge_token);
+        return;
+      }
       localStorage.setItem("token", data.token);
+      localStorage.setItem("refresh_token", data.refresh_token);
       localStorage.setItem("email", data.email);
       navigate("/dashboard");
     } catch (err) {
+      if (err.response?.data?.error?.includes("challenge")) {
+        setChallenge("");
+        setCode("");
+      }
       setError(err.response?.data?.error || "Sign in failed");
     } finally {
       setLoading(false);
@@ -41,34 +54,52 @@ export default function SignIn() {
             </div>
           )}
 
-          <div className="space-y-4">
+          {challenge ? (
             <div>
               <label className="block text-sm font-medium text-gray-700">
-                Email
+                Authentication code
               </label>
               <input
-                type="email"
-                value={email}
-                onChange={(e) => setEmail(e.target.value)}
+                type="text"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
                 required
+                autoFocus
+                autoComplete="one-time-code"
+                placeholder="123456 or a recovery code"
                 className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
               />
             </div>
+          ) : (
+            <div className="space-y-4">
+              <div>
+                <label className="block text-sm font-medium text-gray-700">
+                  Email
+                </label>
+                <input
+                  type="email"
+                  value={email}
+                  onChange={(e) => setEmail(e.target.value)}
+                  required
+                  className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+                />
+              </div>
 
-            <div>
-              <label className="block text-sm font-medium text-gray-700">
-                Password
-              </label>
-              <input
-                type="password"
-                value={password}
-                onChange={(e) => setPassword(e.target.value)}
-                required
-                minLength={8}
-                className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
-              />
+              <div>
+                <label className="block text-sm font-medium text-gray-700">
+                  Password
+                </label>
+                <input
+                  type="password"
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  required
+                  minLength={8}
+                  className="mt-1 block w-full px-3 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+                />
+              </div>
             </div>
-          </div>
+          )}
 
           <button
             type="submit"
@@ -78,6 +109,15 @@ export default function SignIn() {
             {loading ? "Signing in..." : "Sign in"}
           </button>
 
+          <p className="text-center text-sm text-gray-600">
+            <Link
+              to="/forgot-password"
+              className="text-blue-600 hover:text-blue-500"
+            >
+              Forgot your password?
+            </Link>
+          </p>
+
           <p className="text-center text-sm text-gray-600">
             Don't have an account?{" "}
             <Link to="/signup" className="text-blue-600 hover:text-blue-500">
@@ -7,6 +7,7 @@ export default function SignUp() {
   const [password, setPassword] = useState("");
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
+  const [notice, setNotice] = useState("");
   const navigate = useNavigate();
 
   const handleSubmit = async (e) => {
@@ -16,7 +17,12 @@ export default function SignUp() {
 
     try {
       const { data } = await auth.signUp(email, password);
+      if (data.verification_required) {
+        setNotice(`We sent a verification link to ${data.user.email}.`);
+        return;
+      }
       localStorage.setItem("token", data.token);
+      localStorage.setItem("refresh_token", data.refresh_token);
       localStorage.setItem("email", data.user.email);
       navigate("/dashboard");
     } catch (err) {
@@ -41,6 +47,12 @@ export default function SignUp() {
             </div>
           )}
 
+          {notice && (
+            <div className="bg-green-50 text-green-700 p-3 rounded text-sm">
+              {notice}
+            </div>
+          )}
+
           <div className="space-y-4">
             <div>
               <label className="block text-sm font-medium text-gray-700">
@@ -0,0 +1,52 @@
+import { useEffect, useState } from "react";
+import { Link, useSearchParams } from "react-router-dom";
+import { auth } from "../../api/client";
+
+export default function VerifyEmail() {
+  const [searchParams] = useSearchParams();
+  const [status, setStatus] = useState("verifying");
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    const token = searchParams.get("token");
+    if (!token) {
+      setStatus("failed");
+      setError("Verification link is missing its token");
+      return;
+    }
+
+    auth
+      .verifyEmail(token)
+      .then(() => setStatus("verified"))
+      .catch((err) => {
+        setStatus("failed");
+        setError(err.response?.data?.error || "Verification failed");
+      });
+  }, [searchParams]);
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-gray-50">
+      <div className="max-w-md w-full space-y-6 p-8 bg-white rounded-lg shadow">
+        <h2 className="text-3xl font-bold text-gray-900">Atlas</h2>
+
+        {status === "verifying" && (
+          <p className="text-sm text-gray-600">Verifying your email...</p>
+        )}
+        {status === "verified" && (
+          <p className="text-sm text-gray-600">Your email has been verified.</p>
+        )}
+        {status === "failed" && (
+          <div className="bg-red-50 text-red-600 p-3 rounded text-sm">
+            {error}
+          </div>
+        )}
+
+        <p className="text-center text-sm text-gray-600">
+          <Link to="/signin" className="text-blue-600 hover:text-blue-500">
+            Back to sign in
+          </Link>
+        </p>
+      </div>
+    </div>
+  );
+}
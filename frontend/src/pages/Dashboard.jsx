@@ -1,5 +1,5 @@
 import { useState, useEffect } from "react";
-import { projects as projectsAPI } from "../api/client";
+import { auth, projects as projectsAPI } from "../api/client";
 import { useNavigate } from "react-router-dom";
 import CreateOrganization from "../components/projects/CreateOrganization";
 import CreateProject from "../components/projects/CreateProject";
@@ -75,8 +75,13 @@ export default function Dashboard() {
     }
   };
 
-  const handleLogout = () => {
+  const handleLogout = async () => {
+    const refreshToken = localStorage.getItem("refresh_token");
+    if (refreshToken) {
+      await auth.logout(refreshToken).catch(() => {});
+    }
     localStorage.removeItem("token");
+    localStorage.removeItem("refresh_token");
     localStorage.removeItem("email");
     navigate("/signin");
   };
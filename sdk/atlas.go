@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"runtime/debug"
 	"time"
@@ -13,18 +14,44 @@ import (
 
 type Option func(*Client)
 
+const (
+	eventsPath = "/api/ingest/events"
+	batchPath  = "/api/ingest/events/batch"
+)
+
 type Client struct {
-	apiKey  string
-	baseURL string
-	enabled bool
-	client  *http.Client
+	apiKey     string
+	baseURL    string
+	enabled    bool
+	client     *http.Client
+	queueSize  int
+	numWorkers int
+	dropPolicy DropPolicy
+	transport  *transport
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	spoolDir   string
+	spoolMax   int
+	spool      *spool
+	batchSize  int
+	tags       map[string]string
+	release    string
 }
 
 type Event struct {
-	Level      string    `json:"level"`
-	Message    string    `json:"message"`
-	StackTrace string    `json:"stack_trace"`
-	Timestamp  time.Time `json:"timestamp"`
+	Level       string            `json:"level"`
+	Message     string            `json:"message"`
+	StackTrace  string            `json:"stack_trace"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Release     string            `json:"release,omitempty"`
+}
+
+type batchResponse struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
 }
 
 func WithBaseURL(url string) Option {
@@ -39,20 +66,126 @@ func WithEnabled(enabled bool) Option {
 	}
 }
 
+func WithQueueSize(size int) Option {
+	return func(c *Client) {
+		c.queueSize = size
+	}
+}
+
+func WithWorkers(n int) Option {
+	return func(c *Client) {
+		c.numWorkers = n
+	}
+}
+
+func WithDropPolicy(policy DropPolicy) Option {
+	return func(c *Client) {
+		c.dropPolicy = policy
+	}
+}
+
+func WithTags(tags map[string]string) Option {
+	return func(c *Client) {
+		c.tags = tags
+	}
+}
+
+func WithRelease(release string) Option {
+	return func(c *Client) {
+		c.release = release
+	}
+}
+
+func WithBatchSize(n int) Option {
+	return func(c *Client) {
+		c.batchSize = n
+	}
+}
+
+func WithMaxRetries(n int) Option {
+	return func(c *Client) {
+		c.maxRetries = n
+	}
+}
+
+func WithRetryBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+func WithSpoolDir(dir string) Option {
+	return func(c *Client) {
+		c.spoolDir = dir
+	}
+}
+
+func WithSpoolMaxFiles(n int) Option {
+	return func(c *Client) {
+		c.spoolMax = n
+	}
+}
+
 func NewClient(apiKey string, options ...Option) *Client{
 	client := &Client{
-		apiKey:  apiKey,
-		baseURL: "http://localhost:8081",
-		enabled: true,
-		client:  &http.Client{Timeout: 5 * time.Second},
+		apiKey:     apiKey,
+		baseURL:    "http://localhost:8081",
+		enabled:    true,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		queueSize:  100,
+		numWorkers: 2,
+		dropPolicy: DropNewest,
+		maxRetries: 3,
+		minBackoff: 500 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+		spoolMax:   1000,
+		batchSize:  10,
 	}
 	for _, opt := range options{
 		opt(client)
 	}
 
+	if client.enabled && client.spoolDir != "" {
+		spool, err := newSpool(client.spoolDir, client.spoolMax)
+		if err != nil {
+			fmt.Printf("Atlas: Offline spool disabled: %v\n", err)
+		} else {
+			client.spool = spool
+		}
+	}
+
+	if client.enabled {
+		client.transport = newTransport(client, client.queueSize, client.numWorkers, client.dropPolicy)
+	}
+
 	return client
 }
 
+func (c *Client) Flush(timeout time.Duration) bool {
+	if c.transport == nil {
+		return true
+	}
+	return c.transport.flush(timeout)
+}
+
+func (c *Client) Close() {
+	if c.transport == nil {
+		return
+	}
+
+	if !c.transport.close(5 * time.Second) {
+		fmt.Println("Atlas: Timed out flushing events on close")
+	}
+}
+
+func (c *Client) Dropped() int {
+	if c.transport == nil {
+		return 0
+	}
+	return c.transport.droppedCount()
+}
+
 func (c *Client) CaptureError(err error) {
 	if !c.enabled || err == nil {
 		return
@@ -65,7 +198,7 @@ func (c *Client) CaptureError(err error) {
 		Timestamp:  time.Now().UTC(),
 	}
 
-	c.send(event)
+	c.capture(event)
 }
 
 func (c *Client) CaptureMessage(message, level string) {
@@ -80,34 +213,146 @@ func (c *Client) CaptureMessage(message, level string) {
 		Timestamp:  time.Now().UTC(),
 	}
 
-	c.send(event)
+	c.capture(event)
 }
 
-func (c *Client) send(event Event) {
+func (c *Client) CaptureEvent(event Event) {
+	if !c.enabled {
+		return
+	}
+
+	if event.Level == "" {
+		event.Level = "error"
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	c.capture(event)
+}
+
+func (c *Client) capture(event Event) {
+	if c.transport == nil {
+		return
+	}
+
+	if event.Release == "" {
+		event.Release = c.release
+	}
+
+	if len(c.tags) > 0 {
+		tags := make(map[string]string, len(c.tags)+len(event.Tags))
+		for k, v := range c.tags {
+			tags[k] = v
+		}
+		for k, v := range event.Tags {
+			tags[k] = v
+		}
+		event.Tags = tags
+	}
+
+	if !c.transport.enqueue(event) {
+		fmt.Println("Atlas: Event dropped (queue full or client closed)")
+	}
+}
+
+func (c *Client) send(event Event, quit <-chan struct{}) {
 	data, err := json.Marshal(event)
 	if err != nil {
 		fmt.Printf("Atlas: Failed to marshal event: %v\n", err)
 		return
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/api/ingest/events", bytes.NewBuffer(data))
+	_, err = c.sendWithRetry(eventsPath, data, quit)
+	if err == nil {
+		return
+	}
+
+	if c.spool != nil && isRetryable(err) {
+		err = c.spool.store(data)
+		if err != nil {
+			fmt.Printf("Atlas: Failed to spool event: %v\n", err)
+		}
+		return
+	}
+
+	fmt.Printf("Atlas: Failed to send event: %v\n", err)
+}
+
+func (c *Client) spoolEvent(event Event) bool {
+	if c.spool == nil {
+		return false
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return false
+	}
+
+	err = c.spool.store(data)
+	if err != nil {
+		fmt.Printf("Atlas: Failed to spool event: %v\n", err)
+		return false
+	}
+	return true
+}
+
+func (c *Client) sendBatch(events []Event, quit <-chan struct{}) {
+	if len(events) == 1 {
+		c.send(events[0], quit)
+		return
+	}
+
+	data, err := json.Marshal(events)
 	if err != nil {
-		fmt.Printf("Atlas: Failed to create request: %v\n", err)
+		fmt.Printf("Atlas: Failed to marshal batch: %v\n", err)
+		return
+	}
+
+	body, err := c.sendWithRetry(batchPath, data, quit)
+	if err == nil {
+		var resp batchResponse
+		if json.Unmarshal(body, &resp) == nil && resp.Rejected > 0 {
+			fmt.Printf("Atlas: %d of %d events rejected by server\n", resp.Rejected, len(events))
+		}
 		return
 	}
 
+	if c.spool != nil && isRetryable(err) {
+		for _, event := range events {
+			c.spoolEvent(event)
+		}
+		return
+	}
+
+	fmt.Printf("Atlas: Failed to send batch of %d events: %v\n", len(events), err)
+}
+
+func (c *Client) post(path string, data []byte) ([]byte, error) {
+	req, err := http.NewRequest("POST", c.baseURL+path, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-API-Key", c.apiKey)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		fmt.Printf("Atlas: Network error: %v\n", err)
-		return
+		return nil, &deliveryError{retryable: true, err: fmt.Errorf("network error: %w", err)}
 	}
 
 	defer resp.Body.Close()
-	if resp.StatusCode != 202 {
-		fmt.Printf("Atlas: Failed to send event (%d)\n", resp.StatusCode)
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, nil
+	}
+
+	return nil, &deliveryError{
+		status:     resp.StatusCode,
+		retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
 	}
 }
 
@@ -0,0 +1,103 @@
+package atlas
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type deliveryError struct {
+	status     int
+	retryable  bool
+	retryAfter time.Duration
+	err        error
+}
+
+func (e *deliveryError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("unexpected status %d", e.status)
+}
+
+func (e *deliveryError) Unwrap() error {
+	return e.err
+}
+
+func isRetryable(err error) bool {
+	var derr *deliveryError
+	return errors.As(err, &derr) && derr.retryable
+}
+
+func (c *Client) sendWithRetry(path string, data []byte, quit <-chan struct{}) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := c.post(path, data)
+		if err == nil {
+			return body, nil
+		}
+
+		var derr *deliveryError
+		if !errors.As(err, &derr) || !derr.retryable || attempt >= c.maxRetries {
+			return nil, err
+		}
+
+		wait := c.backoff(attempt)
+		if derr.retryAfter > wait {
+			wait = derr.retryAfter
+		}
+		if wait > c.maxBackoff {
+			wait = c.maxBackoff
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-quit:
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.minBackoff << uint(attempt)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+
+	wait := time.Until(at)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
@@ -0,0 +1,142 @@
+package atlas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	client := &Client{minBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	cases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{70, time.Second},
+	}
+	for _, tc := range cases {
+		for i := 0; i < 20; i++ {
+			wait := client.backoff(tc.attempt)
+			if wait < tc.max/2 || wait > tc.max {
+				t.Fatalf("attempt %d: expected a wait between %s and %s, got %s", tc.attempt, tc.max/2, tc.max, wait)
+			}
+		}
+	}
+
+	client.maxBackoff = 0
+	if wait := client.backoff(3); wait != 0 {
+		t.Errorf("expected no wait when backoff is disabled, got %s", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("120"); got != 2*time.Minute {
+		t.Errorf("expected 2m, got %s", got)
+	}
+
+	for _, value := range []string{"", "  ", "-5", "soon"} {
+		if got := parseRetryAfter(value); got != 0 {
+			t.Errorf("%q: expected 0, got %s", value, got)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("expected about 1h for an HTTP date, got %s", got)
+	}
+
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(past); got != 0 {
+		t.Errorf("expected 0 for a date in the past, got %s", got)
+	}
+}
+
+func TestSendWithRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithEnabled(false), WithRetryBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err := client.sendWithRetry(eventsPath, []byte("{}"), nil)
+	if err != nil {
+		t.Fatalf("expected the send to succeed after retries, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestSendWithRetry_StopsOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithEnabled(false), WithRetryBackoff(time.Millisecond, 10*time.Millisecond))
+
+	_, err := client.sendWithRetry(eventsPath, []byte("{}"), nil)
+	if err == nil || isRetryable(err) {
+		t.Fatalf("expected a non-retryable error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestSendWithRetry_ClampsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithEnabled(false), WithMaxRetries(2), WithRetryBackoff(time.Millisecond, 20*time.Millisecond))
+
+	start := time.Now()
+	_, err := client.sendWithRetry(eventsPath, []byte("{}"), nil)
+	if err == nil {
+		t.Fatal("expected the send to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected Retry-After to be capped at the max backoff, took %s", elapsed)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestSendWithRetry_QuitStopsWaiting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithEnabled(false), WithRetryBackoff(time.Hour, time.Hour))
+
+	quit := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(quit) })
+
+	_, err := client.sendWithRetry(eventsPath, []byte("{}"), quit)
+	if !isRetryable(err) {
+		t.Errorf("expected the last retryable error, got %v", err)
+	}
+}
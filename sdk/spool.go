@@ -0,0 +1,121 @@
+package atlas
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const spoolExt = ".json"
+
+// spool keeps at most maxFiles events on disk; storing past that drops the
+// oldest ones.
+type spool struct {
+	dir      string
+	maxFiles int
+	mu       sync.Mutex
+}
+
+func newSpool(dir string, maxFiles int) (*spool, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+
+	return &spool{dir: dir, maxFiles: maxFiles}, nil
+}
+
+func (s *spool) store(data []byte) error {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := fmt.Sprintf("%020d-%s", time.Now().UnixNano(), hex.EncodeToString(suffix))
+
+	tmp := filepath.Join(s.dir, name+".tmp")
+	err := os.WriteFile(tmp, data, 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, filepath.Join(s.dir, name+spoolExt))
+	if err != nil {
+		return err
+	}
+
+	s.trim()
+	return nil
+}
+
+// trim removes the oldest files beyond maxFiles. It does not take s.mu, so a
+// long replay never blocks new events; removing a file replay is about to read
+// only makes replay skip it.
+func (s *spool) trim() {
+	if s.maxFiles <= 0 {
+		return
+	}
+
+	names, err := s.files()
+	if err != nil || len(names) <= s.maxFiles {
+		return
+	}
+
+	excess := names[:len(names)-s.maxFiles]
+	for _, name := range excess {
+		os.Remove(filepath.Join(s.dir, name))
+	}
+	fmt.Printf("Atlas: Spool full, dropped %d oldest events\n", len(excess))
+}
+
+func (s *spool) files() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolExt) {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// replay sends spooled events oldest first and stops at the first retryable
+// failure so ordering is kept until the endpoint is reachable again.
+func (s *spool) replay(send func([]byte) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names, err := s.files()
+	if err != nil {
+		fmt.Printf("Atlas: Failed to read spool: %v\n", err)
+		return
+	}
+
+	for _, name := range names {
+		path := filepath.Join(s.dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		err = send(data)
+		if err != nil && isRetryable(err) {
+			return
+		}
+
+		if err != nil {
+			fmt.Printf("Atlas: Discarding spooled event: %v\n", err)
+		}
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,92 @@
+package atlas
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func newTestSpool(t *testing.T, maxFiles int, events ...string) *spool {
+	t.Helper()
+	s, err := newSpool(t.TempDir(), maxFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range events {
+		err = s.store([]byte(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func spooled(t *testing.T, s *spool) []string {
+	t.Helper()
+	names, err := s.files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []string
+	for _, name := range names {
+		data, err := os.ReadFile(s.dir + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, string(data))
+	}
+	return events
+}
+
+func TestSpool_ReplayOldestFirst(t *testing.T) {
+	s := newTestSpool(t, 0, "a", "b", "c")
+
+	var sent []string
+	s.replay(func(data []byte) error {
+		sent = append(sent, string(data))
+		return nil
+	})
+
+	assertMessages(t, sent, "a", "b", "c")
+	if left := spooled(t, s); len(left) != 0 {
+		t.Errorf("expected the spool to be empty, got %v", left)
+	}
+}
+
+func TestSpool_ReplayStopsOnRetryableError(t *testing.T) {
+	s := newTestSpool(t, 0, "a", "b", "c")
+
+	var sent []string
+	s.replay(func(data []byte) error {
+		sent = append(sent, string(data))
+		if string(data) == "b" {
+			return &deliveryError{status: 503, retryable: true}
+		}
+		return nil
+	})
+
+	assertMessages(t, sent, "a", "b")
+	assertMessages(t, spooled(t, s), "b", "c")
+}
+
+func TestSpool_ReplayDiscardsRejectedEvents(t *testing.T) {
+	s := newTestSpool(t, 0, "a", "b")
+
+	s.replay(func(data []byte) error {
+		if string(data) == "a" {
+			return &deliveryError{status: 400}
+		}
+		return errors.New("unexpected")
+	})
+
+	if left := spooled(t, s); len(left) != 0 {
+		t.Errorf("expected rejected events to be removed, got %v", left)
+	}
+}
+
+func TestSpool_DropsOldestPastLimit(t *testing.T) {
+	s := newTestSpool(t, 3, "a", "b", "c", "d", "e")
+
+	assertMessages(t, spooled(t, s), "c", "d", "e")
+}
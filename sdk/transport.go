@@ -0,0 +1,236 @@
+package atlas
+
+import (
+	"sync"
+	"time"
+)
+
+type DropPolicy int
+
+const spoolReplayInterval = 30 * time.Second
+
+const (
+	DropNewest DropPolicy = iota
+	DropOldest
+	Block
+)
+
+type transport struct {
+	client *Client
+	queue  chan Event
+	quit   chan struct{}
+	policy DropPolicy
+
+	mu      sync.Mutex
+	pending int
+	idle    chan struct{}
+	closed  bool
+	dropped int
+
+	workers sync.WaitGroup
+}
+
+func newTransport(client *Client, queueSize, numWorkers int, policy DropPolicy) *transport {
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+
+	t := &transport{
+		client: client,
+		queue:  make(chan Event, queueSize),
+		quit:   make(chan struct{}),
+		policy: policy,
+		idle:   make(chan struct{}),
+	}
+	close(t.idle)
+
+	for i := 0; i < numWorkers; i++ {
+		t.workers.Add(1)
+		go t.worker()
+	}
+
+	if client.spool != nil {
+		t.workers.Add(1)
+		go t.replayLoop()
+	}
+
+	return t
+}
+
+func (t *transport) enqueue(event Event) bool {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return false
+	}
+	t.track(1)
+	t.mu.Unlock()
+
+	select {
+	case t.queue <- event:
+		return true
+	default:
+	}
+
+	switch t.policy {
+	case Block:
+		select {
+		case t.queue <- event:
+			return true
+		case <-t.quit:
+			t.done()
+			return false
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case t.queue <- event:
+				return true
+			default:
+			}
+
+			select {
+			case <-t.queue:
+				t.drop()
+			default:
+			}
+		}
+
+	default:
+		t.drop()
+		return false
+	}
+}
+
+func (t *transport) worker() {
+	defer t.workers.Done()
+
+	for {
+		select {
+		case event := <-t.queue:
+			batch := t.collect(event)
+			t.client.sendBatch(batch, t.quit)
+			for range batch {
+				t.done()
+			}
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+func (t *transport) collect(first Event) []Event {
+	batch := []Event{first}
+	for len(batch) < t.client.batchSize {
+		select {
+		case event := <-t.queue:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (t *transport) replayLoop() {
+	defer t.workers.Done()
+
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		t.client.spool.replay(func(data []byte) error {
+			_, err := t.client.post(eventsPath, data)
+			return err
+		})
+
+		select {
+		case <-ticker.C:
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+func (t *transport) drop() {
+	t.mu.Lock()
+	t.dropped++
+	t.mu.Unlock()
+	t.done()
+}
+
+func (t *transport) done() {
+	t.mu.Lock()
+	t.track(-1)
+	t.mu.Unlock()
+}
+
+// track must be called with t.mu held.
+func (t *transport) track(delta int) {
+	if t.pending == 0 && delta > 0 {
+		t.idle = make(chan struct{})
+	}
+
+	t.pending += delta
+	if t.pending == 0 {
+		close(t.idle)
+	}
+}
+
+func (t *transport) flush(timeout time.Duration) bool {
+	t.mu.Lock()
+	idle := t.idle
+	t.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-idle:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (t *transport) close(timeout time.Duration) bool {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return true
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	ok := t.flush(timeout)
+	close(t.quit)
+	t.workers.Wait()
+	t.drainToSpool()
+
+	return ok
+}
+
+func (t *transport) drainToSpool() {
+	for {
+		select {
+		case event := <-t.queue:
+			if !t.client.spoolEvent(event) {
+				t.drop()
+				continue
+			}
+			t.done()
+		default:
+			return
+		}
+	}
+}
+
+func (t *transport) droppedCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dropped
+}
@@ -0,0 +1,313 @@
+package atlas
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ingestServer records the messages it receives. While gate is set, each
+// request signals started and then waits for the gate to be closed.
+type ingestServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []string
+	status   int
+	gate     chan struct{}
+	started  chan struct{}
+}
+
+func newIngestServer(t *testing.T) *ingestServer {
+	s := &ingestServer{status: http.StatusAccepted, started: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *ingestServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	gate, status := s.gate, s.status
+	s.mu.Unlock()
+
+	s.started <- struct{}{}
+	if gate != nil {
+		<-gate
+	}
+
+	var events []Event
+	if r.URL.Path == batchPath {
+		json.NewDecoder(r.Body).Decode(&events)
+	} else {
+		var event Event
+		json.NewDecoder(r.Body).Decode(&event)
+		events = []Event{event}
+	}
+
+	s.mu.Lock()
+	if status < 300 {
+		for _, event := range events {
+			s.messages = append(s.messages, event.Message)
+		}
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (s *ingestServer) hold() chan struct{} {
+	gate := make(chan struct{})
+	s.mu.Lock()
+	s.gate = gate
+	s.mu.Unlock()
+	return gate
+}
+
+func (s *ingestServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func (s *ingestServer) waitStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the server to receive a request")
+	}
+}
+
+func newTestClient(s *ingestServer, options ...Option) *Client {
+	options = append([]Option{
+		WithBaseURL(s.URL),
+		WithWorkers(1),
+		WithBatchSize(1),
+	}, options...)
+	return NewClient("test-key", options...)
+}
+
+func assertMessages(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestTransport_SendsAsynchronously(t *testing.T) {
+	server := newIngestServer(t)
+	gate := server.hold()
+
+	client := newTestClient(server)
+	defer client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		client.CaptureMessage("first", "error")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected CaptureMessage to return while the server is busy")
+	}
+
+	close(gate)
+	if !client.Flush(2 * time.Second) {
+		t.Fatal("expected Flush to finish")
+	}
+	assertMessages(t, server.received(), "first")
+}
+
+func TestTransport_Batches(t *testing.T) {
+	server := newIngestServer(t)
+	gate := server.hold()
+
+	client := newTestClient(server, WithBatchSize(10))
+	defer client.Close()
+
+	client.CaptureMessage("first", "error")
+	server.waitStarted(t)
+	client.CaptureMessage("second", "error")
+	client.CaptureMessage("third", "error")
+
+	close(gate)
+	if !client.Flush(2 * time.Second) {
+		t.Fatal("expected Flush to finish")
+	}
+	assertMessages(t, server.received(), "first", "second", "third")
+
+	if len(server.started) != 1 {
+		t.Errorf("expected the queued events to go in one batch request, got %d requests", len(server.started))
+	}
+}
+
+// fillQueue sends "first", waits until the worker is stuck sending it, then
+// fills the one-slot queue with "second".
+func fillQueue(t *testing.T, server *ingestServer, policy DropPolicy) (*Client, chan struct{}) {
+	gate := server.hold()
+	client := newTestClient(server, WithQueueSize(1), WithDropPolicy(policy))
+
+	client.CaptureMessage("first", "error")
+	server.waitStarted(t)
+	client.CaptureMessage("second", "error")
+
+	return client, gate
+}
+
+func TestTransport_DropNewest(t *testing.T) {
+	server := newIngestServer(t)
+	client, gate := fillQueue(t, server, DropNewest)
+	defer client.Close()
+
+	client.CaptureMessage("third", "error")
+	if client.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", client.Dropped())
+	}
+
+	close(gate)
+	client.Flush(2 * time.Second)
+	assertMessages(t, server.received(), "first", "second")
+}
+
+func TestTransport_DropOldest(t *testing.T) {
+	server := newIngestServer(t)
+	client, gate := fillQueue(t, server, DropOldest)
+	defer client.Close()
+
+	client.CaptureMessage("third", "error")
+	if client.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", client.Dropped())
+	}
+
+	close(gate)
+	client.Flush(2 * time.Second)
+	assertMessages(t, server.received(), "first", "third")
+}
+
+func TestTransport_Block(t *testing.T) {
+	server := newIngestServer(t)
+	client, gate := fillQueue(t, server, Block)
+	defer client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		client.CaptureMessage("third", "error")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected CaptureMessage to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(gate)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected CaptureMessage to return once the queue drains")
+	}
+
+	client.Flush(2 * time.Second)
+	assertMessages(t, server.received(), "first", "second", "third")
+	if client.Dropped() != 0 {
+		t.Errorf("expected no dropped events, got %d", client.Dropped())
+	}
+}
+
+func TestTransport_FlushTimeout(t *testing.T) {
+	server := newIngestServer(t)
+	gate := server.hold()
+
+	client := newTestClient(server)
+	defer client.Close()
+
+	client.CaptureMessage("first", "error")
+
+	start := time.Now()
+	if client.Flush(50 * time.Millisecond) {
+		t.Fatal("expected Flush to time out while the server is busy")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("expected Flush to return after its timeout, took %s", time.Since(start))
+	}
+
+	close(gate)
+	if !client.Flush(2 * time.Second) {
+		t.Fatal("expected Flush to finish once the server responds")
+	}
+}
+
+func TestTransport_FlushIdle(t *testing.T) {
+	server := newIngestServer(t)
+	client := newTestClient(server)
+	defer client.Close()
+
+	if !client.Flush(time.Millisecond) {
+		t.Fatal("expected Flush with nothing queued to return true")
+	}
+}
+
+func TestTransport_CloseDrainsQueue(t *testing.T) {
+	server := newIngestServer(t)
+	gate := server.hold()
+
+	client := newTestClient(server, WithQueueSize(10))
+	client.CaptureMessage("first", "error")
+	server.waitStarted(t)
+	client.CaptureMessage("second", "error")
+	client.CaptureMessage("third", "error")
+
+	time.AfterFunc(50*time.Millisecond, func() { close(gate) })
+	client.Close()
+
+	assertMessages(t, server.received(), "first", "second", "third")
+
+	client.CaptureMessage("late", "error")
+	client.Flush(100 * time.Millisecond)
+	if len(server.received()) != 3 {
+		t.Errorf("expected events captured after Close to be discarded, got %v", server.received())
+	}
+}
+
+func TestTransport_CloseSpoolsUnsentEvents(t *testing.T) {
+	server := newIngestServer(t)
+	server.status = http.StatusServiceUnavailable
+	dir := t.TempDir()
+
+	client := newTestClient(server,
+		WithQueueSize(10),
+		WithSpoolDir(dir),
+		WithRetryBackoff(time.Hour, time.Hour),
+	)
+	for i := 0; i < 5; i++ {
+		client.CaptureMessage("event", "error")
+	}
+	server.waitStarted(t)
+
+	if client.transport.close(50 * time.Millisecond) {
+		t.Fatal("expected close to report the flush timeout")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Errorf("expected 5 spooled events, got %d", len(entries))
+	}
+	if client.Dropped() != 0 {
+		t.Errorf("expected no dropped events, got %d", client.Dropped())
+	}
+}
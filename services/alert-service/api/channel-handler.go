@@ -0,0 +1,129 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/services/alert-service/notifier"
+	"gorm.io/gorm"
+)
+
+type ChannelRequest struct {
+	Type   string `json:"type"   binding:"required,oneof=webhook email slack"`
+	Target string `json:"target" binding:"required"`
+	Secret string `json:"secret"`
+}
+
+// newChannel validates req. Webhook channels without a secret get a
+// generated one, returned once in SigningSecret.
+func (h *AlertHandler) newChannel(ruleID string, req ChannelRequest) (models.AlertChannel, error) {
+	target, err := notifier.ValidateTarget(req.Type, strings.TrimSpace(req.Target), h.Config.NOTIFY.AllowPrivateTargets)
+	if err != nil {
+		return models.AlertChannel{}, err
+	}
+
+	channel := models.AlertChannel{
+		RuleID: ruleID,
+		Type:   req.Type,
+		Target: target,
+		Secret: req.Secret,
+	}
+
+	if channel.Type == models.ChannelWebhook && channel.Secret == "" {
+		channel.Secret = generateSecret()
+		channel.SigningSecret = channel.Secret
+	}
+
+	return channel, nil
+}
+
+func (h *AlertHandler) findRule(c *gin.Context) (models.AlertRule, bool) {
+	var rule models.AlertRule
+	result := h.DB.Where("id = ? AND project_id = ?", c.Param("rule_id"), c.Param("project_id")).First(&rule)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return rule, false
+	}
+	return rule, true
+}
+
+func (h *AlertHandler) GetChannels(c *gin.Context) {
+	rule, ok := h.findRule(c)
+	if !ok {
+		return
+	}
+
+	var channels []models.AlertChannel
+	result := h.DB.Where("rule_id = ?", rule.ID).Order("created_at").Find(&channels)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch channels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"channels": channels})
+}
+
+func (h *AlertHandler) CreateChannel(c *gin.Context) {
+	rule, ok := h.findRule(c)
+	if !ok {
+		return
+	}
+
+	var req ChannelRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	channel, err := h.newChannel(rule.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := h.DB.Create(&channel)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create channel"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"channel": channel})
+}
+
+func (h *AlertHandler) DeleteChannel(c *gin.Context) {
+	rule, ok := h.findRule(c)
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("id = ? AND rule_id = ?", c.Param("channel_id"), rule.ID).Delete(&models.AlertChannel{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete channel"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func createChannels(tx *gorm.DB, channels []models.AlertChannel) error {
+	if len(channels) == 0 {
+		return nil
+	}
+	return tx.Create(&channels).Error
+}
+
+func generateSecret() string {
+	randomBytes := make([]byte, 32)
+	rand.Read(randomBytes)
+	return "whsec_" + hex.EncodeToString(randomBytes)
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/services/alert-service/notifier"
+	"gorm.io/gorm"
+)
+
+// DeliveryQueue hands deliveries to the notification workers.
+type DeliveryQueue interface {
+	Enqueue(deliveryID string) error
+}
+
+// notify records a pending delivery of event for each of the rule's channels
+// and queues it. Sending happens on the notification workers so a slow
+// receiver does not hold up the issue event consumer.
+func (h *AlertHandler) notify(rule models.AlertRule, alert models.AlertLog, event string) {
+	var channels []models.AlertChannel
+	result := h.DB.Where("rule_id = ?", rule.ID).Find(&channels)
+	if result.Error != nil {
+		log.Printf("Failed to load channels for rule %s: %v", rule.ID, result.Error)
+		return
+	}
+
+	for _, channel := range channels {
+		delivery := models.Delivery{
+			AlertLogID:  alert.ID,
+			ChannelID:   channel.ID,
+			ChannelType: channel.Type,
+			Event:       event,
+			Status:      models.DeliveryPending,
+		}
+
+		result := h.DB.Create(&delivery)
+		if result.Error != nil {
+			log.Printf("Failed to record delivery for alert %s: %v", alert.ID, result.Error)
+			continue
+		}
+
+		h.dispatch(delivery.ID)
+	}
+}
+
+// dispatch claims a pending delivery and queues it. It never sends: with no
+// queue, or when queueing fails, the delivery stays pending with queued_at
+// unset and RequeuePending picks it up later.
+func (h *AlertHandler) dispatch(deliveryID string) {
+	if h.Queue == nil {
+		return
+	}
+
+	result := h.DB.Model(&models.Delivery{}).
+		Where("id = ? AND status = ? AND queued_at IS NULL", deliveryID, models.DeliveryPending).
+		Update("queued_at", time.Now())
+	if result.Error != nil {
+		log.Printf("Failed to claim delivery %s: %v", deliveryID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	err := h.Queue.Enqueue(deliveryID)
+	if err != nil {
+		log.Printf("Failed to queue delivery %s, will try again: %v", deliveryID, err)
+		h.DB.Model(&models.Delivery{}).Where("id = ?", deliveryID).Update("queued_at", nil)
+	}
+}
+
+// RequeuePending queues pending deliveries that never made it onto the queue.
+func (h *AlertHandler) RequeuePending() error {
+	if h.Queue == nil {
+		return nil
+	}
+
+	var ids []string
+	result := h.DB.Model(&models.Delivery{}).
+		Where("status = ? AND queued_at IS NULL", models.DeliveryPending).
+		Order("created_at").
+		Limit(100).
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return fmt.Errorf("failed to load pending deliveries: %w", result.Error)
+	}
+
+	for _, id := range ids {
+		h.dispatch(id)
+	}
+
+	return nil
+}
+
+func (h *AlertHandler) RunRequeue(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := h.RequeuePending()
+		if err != nil {
+			log.Printf("Failed to requeue deliveries: %v", err)
+		}
+	}
+}
+
+// Deliver makes one attempt at a delivery and records the outcome. It returns
+// an error when the attempt failed and may be retried; final marks the last
+// attempt, after which the delivery is left failed.
+func (h *AlertHandler) Deliver(deliveryID string, final bool) error {
+	var delivery models.Delivery
+	result := h.DB.Where("id = ?", deliveryID).First(&delivery)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if delivery.Status == models.DeliveryDelivered {
+		return nil
+	}
+
+	notification, channel, err := h.loadNotification(delivery)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		h.record(&delivery, 0, errors.New("channel or alert was deleted"), true)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	code, err := h.send(channel, notification)
+	h.record(&delivery, code, err, final)
+	if err != nil {
+		log.Printf("Failed to deliver alert %s to %s channel %s: %v", delivery.AlertLogID, channel.Type, channel.ID, err)
+	}
+
+	return err
+}
+
+func (h *AlertHandler) loadNotification(delivery models.Delivery) (notifier.Notification, models.AlertChannel, error) {
+	var channel models.AlertChannel
+	err := h.DB.Where("id = ?", delivery.ChannelID).First(&channel).Error
+	if err != nil {
+		return notifier.Notification{}, channel, err
+	}
+
+	var alert models.AlertLog
+	err = h.DB.Where("id = ?", delivery.AlertLogID).First(&alert).Error
+	if err != nil {
+		return notifier.Notification{}, channel, err
+	}
+
+	var rule models.AlertRule
+	err = h.DB.Where("id = ?", alert.RuleID).First(&rule).Error
+	if err != nil {
+		return notifier.Notification{}, channel, err
+	}
+
+	notification := notifier.Notification{
+		Event:     delivery.Event,
+		AlertID:   alert.ID,
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		ProjectID: alert.ProjectID,
+		IssueID:   alert.IssueID,
+		Message:   alert.Message,
+		FiredAt:   alert.FiredAt,
+	}
+	if delivery.Event == notifier.EventAlertResolved {
+		notification.Message = "Resolved: " + alert.Message
+		notification.ResolvedAt = alert.ResolvedAt
+	}
+
+	return notification, channel, nil
+}
+
+func (h *AlertHandler) record(delivery *models.Delivery, code int, err error, final bool) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseCode = code
+
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryDelivered
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+	case final:
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = models.DeliveryPending
+		delivery.Error = err.Error()
+	}
+
+	result := h.DB.Save(delivery)
+	if result.Error != nil {
+		log.Printf("Failed to update delivery %s: %v", delivery.ID, result.Error)
+	}
+}
+
+func (h *AlertHandler) send(channel models.AlertChannel, n notifier.Notification) (int, error) {
+	sender, err := notifier.New(channel, h.Config)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.Config.NOTIFY.Timeout)
+	defer cancel()
+
+	return sender.Notify(ctx, n)
+}
+
+// GetDeliveries lists the project's deliveries, failed ones by default.
+func (h *AlertHandler) GetDeliveries(c *gin.Context) {
+	projectID := c.Param("project_id")
+	status := c.DefaultQuery("status", models.DeliveryFailed)
+
+	query := h.DB.Joins("JOIN alert_logs ON alert_logs.id = deliveries.alert_log_id").
+		Where("alert_logs.project_id = ?", projectID)
+	if status != "all" {
+		query = query.Where("deliveries.status = ?", status)
+	}
+
+	var deliveries []models.Delivery
+	result := query.Order("deliveries.created_at desc").Limit(100).Find(&deliveries)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// RetryDelivery puts a failed delivery back on the queue with a fresh set of
+// attempts. If it cannot be queued now, RequeuePending queues it later.
+func (h *AlertHandler) RetryDelivery(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	var delivery models.Delivery
+	result := h.DB.Joins("JOIN alert_logs ON alert_logs.id = deliveries.alert_log_id").
+		Where("deliveries.id = ? AND alert_logs.project_id = ?", c.Param("delivery_id"), projectID).
+		First(&delivery)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	result = h.DB.Model(&models.Delivery{}).
+		Where("id = ? AND status = ?", delivery.ID, models.DeliveryFailed).
+		Updates(map[string]interface{}{"status": models.DeliveryPending, "queued_at": nil})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry delivery"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only failed deliveries can be retried"})
+		return
+	}
+
+	h.dispatch(delivery.ID)
+
+	h.DB.Where("id = ?", delivery.ID).First(&delivery)
+	c.JSON(http.StatusAccepted, gin.H{"delivery": delivery})
+}
@@ -0,0 +1,213 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/alert-service/api"
+	"github.com/k1ngalph0x/atlas/services/alert-service/config"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testProjectID = "00000000-0000-0000-0000-000000000001"
+
+type recordingQueue struct {
+	enqueued []string
+	err      error
+}
+
+func (q *recordingQueue) Enqueue(deliveryID string) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.enqueued = append(q.enqueued, deliveryID)
+	return nil
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "alerts.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	err = db.AutoMigrate(&models.AlertRule{}, &models.AlertLog{}, &models.AlertChannel{}, &models.Delivery{}, &models.AlertState{})
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func setupHandler(db *gorm.DB, queue api.DeliveryQueue) (*api.AlertHandler, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	h := api.NewAlertHandler(db, &config.Config{NOTIFY: config.NotifyConfig{Timeout: time.Second, AllowPrivateTargets: true}}, queue)
+	r := gin.New()
+	project := r.Group("/projects/:project_id")
+	project.POST("/rules", h.CreateAlertRule)
+	project.POST("/rules/test", h.TestExpression)
+	project.GET("/deliveries", h.GetDeliveries)
+	project.POST("/deliveries/:delivery_id/retry", h.RetryDelivery)
+	return h, r
+}
+
+func request(r *gin.Engine, method string, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// receiver fails the first failures requests and accepts the rest.
+func receiver(t *testing.T, failures int32) *httptest.Server {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func createRule(t *testing.T, db *gorm.DB, condition string, webhookURL string) models.AlertRule {
+	t.Helper()
+	rule := models.AlertRule{ProjectID: testProjectID, Name: "Test rule", Condition: condition, IsActive: true}
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("failed to create rule: %v", err)
+	}
+	if webhookURL != "" {
+		db.Create(&models.AlertChannel{RuleID: rule.ID, Type: models.ChannelWebhook, Target: webhookURL, Secret: "whsec_test"})
+	}
+	return rule
+}
+
+func loadDelivery(t *testing.T, db *gorm.DB, id string) models.Delivery {
+	t.Helper()
+	var delivery models.Delivery
+	if err := db.Where("id = ?", id).First(&delivery).Error; err != nil {
+		t.Fatalf("failed to load delivery: %v", err)
+	}
+	return delivery
+}
+
+func TestDelivery_RetryAndManualRetry(t *testing.T) {
+	db := setupTestDB(t)
+	queue := &recordingQueue{}
+	h, r := setupHandler(db, queue)
+	createRule(t, db, "new_issue", receiver(t, 2).URL)
+
+	err := h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 1, Level: "error"})
+	if err != nil {
+		t.Fatalf("process alert: %v", err)
+	}
+	if len(queue.enqueued) != 1 {
+		t.Fatalf("expected one queued delivery, got %d", len(queue.enqueued))
+	}
+	id := queue.enqueued[0]
+	if d := loadDelivery(t, db, id); d.Status != models.DeliveryPending || d.Attempts != 0 {
+		t.Fatalf("expected an unsent pending delivery, got %+v", d)
+	}
+
+	if err := h.Deliver(id, false); err == nil {
+		t.Fatal("expected first attempt to fail")
+	}
+	if d := loadDelivery(t, db, id); d.Status != models.DeliveryPending || d.Attempts != 1 || d.ResponseCode != 500 {
+		t.Errorf("expected pending delivery after a retryable failure, got %+v", d)
+	}
+
+	h.Deliver(id, true)
+	if d := loadDelivery(t, db, id); d.Status != models.DeliveryFailed || d.Attempts != 2 {
+		t.Fatalf("expected failed delivery after the last attempt, got %+v", d)
+	}
+
+	w := request(r, http.MethodGet, "/projects/"+testProjectID+"/deliveries")
+	var listed struct {
+		Deliveries []models.Delivery `json:"deliveries"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	if len(listed.Deliveries) != 1 || listed.Deliveries[0].ID != id {
+		t.Errorf("expected the failed delivery to be listed, got %s", w.Body.String())
+	}
+
+	if w := request(r, http.MethodPost, "/projects/other/deliveries/"+id+"/retry"); w.Code != http.StatusNotFound {
+		t.Errorf("other project: expected 404, got %d", w.Code)
+	}
+	if w := request(r, http.MethodPost, "/projects/"+testProjectID+"/deliveries/"+id+"/retry"); w.Code != http.StatusAccepted {
+		t.Fatalf("retry: expected 202, got %d", w.Code)
+	}
+	if len(queue.enqueued) != 2 || queue.enqueued[1] != id {
+		t.Fatalf("expected retry to queue the delivery again, got %v", queue.enqueued)
+	}
+	if w := request(r, http.MethodPost, "/projects/"+testProjectID+"/deliveries/"+id+"/retry"); w.Code != http.StatusConflict {
+		t.Errorf("retry of pending delivery: expected 409, got %d", w.Code)
+	}
+
+	if err := h.Deliver(id, false); err != nil {
+		t.Fatalf("expected retried delivery to succeed: %v", err)
+	}
+	if d := loadDelivery(t, db, id); d.Status != models.DeliveryDelivered || d.Attempts != 3 || d.ResponseCode != 200 || d.DeliveredAt == nil {
+		t.Errorf("expected delivered delivery, got %+v", d)
+	}
+}
+
+func TestDelivery_RequeuePending(t *testing.T) {
+	db := setupTestDB(t)
+	queue := &recordingQueue{err: errors.New("connection closed")}
+	h, _ := setupHandler(db, queue)
+	createRule(t, db, "critical_error", receiver(t, 0).URL)
+
+	err := h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 3, Level: "critical"})
+	if err != nil {
+		t.Fatalf("process alert: %v", err)
+	}
+
+	var delivery models.Delivery
+	db.First(&delivery)
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 0 || delivery.QueuedAt != nil {
+		t.Fatalf("expected an unsent, unqueued delivery when queueing fails, got %+v", delivery)
+	}
+
+	queue.err = nil
+	queue.enqueued = nil
+	if err := h.RequeuePending(); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	if err := h.RequeuePending(); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	if len(queue.enqueued) != 1 || queue.enqueued[0] != delivery.ID {
+		t.Fatalf("expected the delivery to be queued once, got %v", queue.enqueued)
+	}
+	if d := loadDelivery(t, db, delivery.ID); d.QueuedAt == nil || d.Attempts != 0 {
+		t.Errorf("expected a queued delivery that has not been sent, got %+v", d)
+	}
+}
+
+func TestDelivery_NoQueueNeverSends(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, nil)
+	createRule(t, db, "critical_error", receiver(t, 0).URL)
+
+	err := h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 3, Level: "critical"})
+	if err != nil {
+		t.Fatalf("process alert: %v", err)
+	}
+
+	var delivery models.Delivery
+	db.First(&delivery)
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("expected the delivery to wait for the queue, got %+v", delivery)
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/alert-service/expr"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+)
+
+const dryRunDefaultLimit = 100
+
+type TestExpressionRequest struct {
+	Expression string `json:"expression" binding:"required"`
+	Limit      int    `json:"limit" binding:"min=0,max=500"`
+}
+
+type dryRunIssue struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Level    string    `json:"level"`
+	Status   string    `json:"status"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// issueEnv exposes an issue update to expressions.
+type issueEnv models.IssueUpdateEvent
+
+func (e issueEnv) String(field string) string {
+	switch field {
+	case "issue_id":
+		return e.IssueID
+	case "level":
+		return e.Level
+	case "status":
+		return e.Status
+	case "previous_status":
+		return e.PreviousStatus
+	}
+	return e.Tags[strings.TrimPrefix(field, expr.TagPrefix)]
+}
+
+func (e issueEnv) Number(field string) float64 {
+	if field == "count" {
+		return float64(e.Count)
+	}
+	return 0
+}
+
+// expressionError turns a compile error into the response body for a 400.
+func expressionError(err error) gin.H {
+	var compileErr *expr.Error
+	if errors.As(err, &compileErr) {
+		return gin.H{"error": "invalid expression: " + compileErr.Error(), "position": compileErr.Pos}
+	}
+	return gin.H{"error": "invalid expression: " + err.Error()}
+}
+
+// program returns the compiled expression for rule. Rules cannot be edited,
+// so programs are cached by expression text.
+func (h *AlertHandler) program(rule models.AlertRule) (*expr.Program, error) {
+	cached, ok := h.programs.Load(rule.Expression)
+	if ok {
+		return cached.(*expr.Program), nil
+	}
+
+	program, err := expr.Compile(rule.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	h.programs.Store(rule.Expression, program)
+	return program, nil
+}
+
+func (h *AlertHandler) matchExpression(rule models.AlertRule, e models.IssueUpdateEvent) bool {
+	program, err := h.program(rule)
+	if err != nil {
+		log.Printf("Skipping rule %s with invalid expression: %v", rule.ID, err)
+		return false
+	}
+	return program.Match(issueEnv(e))
+}
+
+// TestExpression runs an expression against the project's most recently seen
+// issues without creating a rule. Issues carry no previous status or tags, so
+// those fields are empty.
+func (h *AlertHandler) TestExpression(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	var req TestExpressionRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	program, err := expr.Compile(req.Expression)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, expressionError(err))
+		return
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = dryRunDefaultLimit
+	}
+
+	var issues []dryRunIssue
+	result := h.DB.Table("issues").
+		Select("id, title, level, status, count, last_seen").
+		Where("project_id = ?", projectID).
+		Order("last_seen desc").
+		Limit(limit).
+		Scan(&issues)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch issues"})
+		return
+	}
+
+	matches := []dryRunIssue{}
+	for _, issue := range issues {
+		e := models.IssueUpdateEvent{
+			IssueID:   issue.ID,
+			ProjectID: projectID,
+			Count:     issue.Count,
+			Level:     issue.Level,
+			Status:    issue.Status,
+		}
+		if program.Match(issueEnv(e)) {
+			matches = append(matches, issue)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tested": len(issues), "matched": len(matches), "issues": matches})
+}
@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+)
+
+// issue mirrors the columns of issue-service's issues table that the dry run
+// reads.
+type issue struct {
+	ID        string
+	ProjectID string
+	Title     string
+	Level     string
+	Status    string
+	Count     int
+	LastSeen  time.Time
+}
+
+func TestCreateAlertRule_Expression(t *testing.T) {
+	db := setupTestDB(t)
+	_, r := setupHandler(db, nil)
+	path := "/projects/" + testProjectID + "/rules"
+
+	w := postJSON(r, path, map[string]any{"name": "prod", "condition": "expression", "expression": "level == critical and count >"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid expression: expected 400, got %d", w.Code)
+	}
+	var body struct {
+		Error    string `json:"error"`
+		Position int    `json:"position"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Position != 30 || body.Error != "invalid expression: expected a value for count, got end of expression at position 30" {
+		t.Errorf("expected the error and its position, got %+v", body)
+	}
+
+	if w := postJSON(r, path, map[string]any{"name": "prod", "condition": "expression"}); w.Code != http.StatusBadRequest {
+		t.Errorf("missing expression: expected 400, got %d", w.Code)
+	}
+
+	w = postJSON(r, path, map[string]any{"name": "prod", "condition": "expression", "expression": "level == critical and tags.environment == production and count > 20"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d — body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProcessAlert_Expression(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, nil)
+	rule := models.AlertRule{
+		ProjectID:  testProjectID,
+		Name:       "prod",
+		Condition:  "expression",
+		Expression: "level == critical and tags.environment == production and count > 20",
+		IsActive:   true,
+	}
+	db.Create(&rule)
+
+	production := map[string]string{"environment": "production"}
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 25, Level: "critical", Status: "open", Tags: map[string]string{"environment": "staging"}})
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-2", ProjectID: testProjectID, Count: 5, Level: "critical", Status: "open", Tags: production})
+	if n := countAlerts(db, rule.ID); n != 0 {
+		t.Fatalf("expected no alerts, got %d", n)
+	}
+
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-2", ProjectID: testProjectID, Count: 21, Level: "critical", Status: "open", Tags: production})
+	if n := countAlerts(db, rule.ID); n != 1 {
+		t.Fatalf("expected one alert, got %d", n)
+	}
+}
+
+func TestTestExpression(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&issue{}); err != nil {
+		t.Fatalf("failed to migrate issues: %v", err)
+	}
+	_, r := setupHandler(db, nil)
+	path := "/projects/" + testProjectID + "/rules/test"
+
+	now := time.Now()
+	for i, level := range []string{"critical", "error", "critical", "warning"} {
+		db.Create(&issue{
+			ID:        fmt.Sprintf("issue-%d", i),
+			ProjectID: testProjectID,
+			Title:     "boom",
+			Level:     level,
+			Status:    "open",
+			Count:     10 * (i + 1),
+			LastSeen:  now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	db.Create(&issue{ID: "other", ProjectID: "other-project", Level: "critical", Count: 100, LastSeen: now})
+
+	w := postJSON(r, path, map[string]any{"expression": "level == critical and count > 15"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Tested  int `json:"tested"`
+		Matched int `json:"matched"`
+		Issues  []struct {
+			ID string `json:"id"`
+		} `json:"issues"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Tested != 4 || body.Matched != 1 || body.Issues[0].ID != "issue-2" {
+		t.Errorf("expected issue-2 to match out of 4, got %+v", body)
+	}
+
+	w = postJSON(r, path, map[string]any{"expression": "level in (critical, error)", "limit": 2})
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Tested != 2 || body.Matched != 2 {
+		t.Errorf("expected the 2 most recent issues to match, got %+v", body)
+	}
+
+	if w := postJSON(r, path, map[string]any{"expression": "lvl == critical"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid expression: expected 400, got %d", w.Code)
+	}
+}
@@ -2,33 +2,47 @@ package api
 
 import (
 	"fmt"
-	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/k1ngalph0x/atlas/services/alert-service/config"
+	"github.com/k1ngalph0x/atlas/services/alert-service/expr"
 	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/services/alert-service/window"
+	"github.com/k1ngalph0x/atlas/shared/auth"
 	"gorm.io/gorm"
 )
 
 type AlertHandler struct {
 	DB     *gorm.DB
 	Config *config.Config
+	Queue  DeliveryQueue
+	Window *window.Counter
+	programs sync.Map
 	//Writer *kafka.Writer
 }
 
-func NewAlertHandler(db *gorm.DB, config *config.Config) *AlertHandler {
+func NewAlertHandler(db *gorm.DB, config *config.Config, queue DeliveryQueue) *AlertHandler {
 	return &AlertHandler{
 		DB: db,
 		Config: config,
+		Queue: queue,
+		Window: window.NewCounter(),
 	}
 }
 
 type CreateRuleRequest struct {
 	Name      string `json:"name"      binding:"required"`
-	Condition string `json:"condition" binding:"required,oneof=new_issue critical_error count_threshold"`
+	Condition string `json:"condition" binding:"required,oneof=new_issue critical_error count_threshold regression event_frequency distinct_issues expression"`
 	Threshold int    `json:"threshold"`
+	WindowMinutes int `json:"window_minutes"`
+	CooldownMinutes int `json:"cooldown_minutes" binding:"min=0,max=10080"`
+	ResolveAfterMinutes int `json:"resolve_after_minutes" binding:"min=0,max=10080"`
+	Expression string `json:"expression"`
+	Channels  []ChannelRequest `json:"channels" binding:"dive"`
 }
 
 func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
@@ -46,19 +60,72 @@ func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
 		return
 	}
 
+	if windowed(req.Condition){
+		if req.Threshold <= 0{
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be > 0 for " + req.Condition + " condition"})
+			return
+		}
+		if req.WindowMinutes <= 0 || time.Duration(req.WindowMinutes)*time.Minute > window.Retention{
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("window_minutes must be between 1 and %d for %s condition", int(window.Retention.Minutes()), req.Condition)})
+			return
+		}
+	} else {
+		req.WindowMinutes = 0
+	}
+
+	if req.Condition == "expression"{
+		req.Expression = strings.TrimSpace(req.Expression)
+		if req.Expression == ""{
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expression is required for expression condition"})
+			return
+		}
+		_, err = expr.Compile(req.Expression)
+		if err != nil{
+			c.JSON(http.StatusBadRequest, expressionError(err))
+			return
+		}
+	} else {
+		req.Expression = ""
+	}
+
 	rule := models.AlertRule{
 		ProjectID: projectID,
 		Name:      req.Name,
 		Condition: req.Condition,
 		Threshold: req.Threshold,
+		WindowMinutes: req.WindowMinutes,
+		CooldownMinutes: req.CooldownMinutes,
+		ResolveAfterMinutes: req.ResolveAfterMinutes,
+		Expression: req.Expression,
 		IsActive:  true,
 	}
 
-	result := h.DB.Create(&rule)
-	if result.Error != nil{
+	channels := make([]models.AlertChannel, 0, len(req.Channels))
+	for _, channelReq := range req.Channels{
+		channel, err := h.newChannel("", channelReq)
+		if err != nil{
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		channels = append(channels, channel)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&rule).Error
+		if err != nil{
+			return err
+		}
+
+		for i := range channels{
+			channels[i].RuleID = rule.ID
+		}
+		return createChannels(tx, channels)
+	})
+	if err != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert rule"})
 		return
 	}
+	rule.Channels = channels
 
 	c.JSON(http.StatusCreated, gin.H{"rule": rule})
 }
@@ -67,7 +134,7 @@ func (h *AlertHandler) GetAlertRules(c *gin.Context) {
 	projectID := c.Param("project_id")
 
 	var rules []models.AlertRule
-	result :=  h.DB.Where("project_id = ?", projectID).Order("created_at desc").Find(&rules)
+	result :=  h.DB.Preload("Channels").Where("project_id = ?", projectID).Order("created_at desc").Find(&rules)
 	if result.Error != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert rules"})
 		return
@@ -80,13 +147,30 @@ func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
 	projectID := c.Param("project_id")
 	ruleID := c.Param("rule_id")
 
-	result := h.DB.Where("id = ? AND project_id = ?", ruleID, projectID).Delete(&models.AlertRule{})
-	if result.Error != nil {
+	var deleted int64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND project_id = ?", ruleID, projectID).Delete(&models.AlertRule{})
+		if result.Error != nil{
+			return result.Error
+		}
+
+		deleted = result.RowsAffected
+		if deleted == 0{
+			return nil
+		}
+
+		err := tx.Where("rule_id = ?", ruleID).Delete(&models.AlertState{}).Error
+		if err != nil{
+			return err
+		}
+		return tx.Where("rule_id = ?", ruleID).Delete(&models.AlertChannel{}).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert rule"})
 		return
 	}
 
-	if result.RowsAffected == 0 {
+	if deleted == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
 		return
 	}
@@ -95,27 +179,45 @@ func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
 }
 
 
-func(h *AlertHandler) ProcessAlert(e models.IssueUpdateEvent){
+func(h *AlertHandler) ProcessAlert(e models.IssueUpdateEvent) error {
 	var rules []models.AlertRule
 
-	result := h.DB.Where("project_id = ? AND is_active = true", e.ProjectID).Find(&rules)
-	if result.Error != nil{
-		log.Printf("Failed to fetch alert rules: %v", result.Error)
-		return
+	now := time.Now()
+	inactive := e.Status == "resolved" || e.Status == "ignored" || e.Status == "snoozed"
+	h.Window.Observe(e.ProjectID, e.IssueID, e.Count, !inactive, now)
+
+	if e.Status == "resolved" {
+		return h.resolveIssue(e.IssueID, now)
 	}
 
-	if len(rules) == 0{
-		return 
+	if inactive {
+		return nil
+	}
+
+	result := h.DB.Where("project_id = ? AND is_active = true", e.ProjectID).Find(&rules)
+	if result.Error != nil{
+		return fmt.Errorf("failed to fetch alert rules: %w", result.Error)
 	}
 
 	for _, rule := range rules{
-		if h.checkRule(rule, e){
-			h.Alert(rule, e)
+		if h.checkRule(rule, e, now){
+			err := h.Alert(rule, e, now)
+			if err != nil{
+				return err
+			}
 		}
 	}
+
+	return nil
+}
+
+func windowed(condition string) bool {
+	return condition == "event_frequency" || condition == "distinct_issues"
 }
 
-func (h *AlertHandler) checkRule(rule models.AlertRule, e models.IssueUpdateEvent) bool {
+// checkRule reports whether the rule's condition holds for the update. Alert
+// decides whether that starts a new alert.
+func (h *AlertHandler) checkRule(rule models.AlertRule, e models.IssueUpdateEvent, now time.Time) bool {
 	switch rule.Condition{
 	case "new_issue":
 		return e.Count == 1
@@ -126,34 +228,21 @@ func (h *AlertHandler) checkRule(rule models.AlertRule, e models.IssueUpdateEven
 	case "count_threshold": 
 		return e.Count > rule.Threshold
 
-	default:
-		return false
-	}
-}
+	case "regression":
+		return e.Status == "regressed" && e.PreviousStatus == "resolved"
 
-func(h *AlertHandler) Alert(rule models.AlertRule, e models.IssueUpdateEvent){
-	var existing models.AlertLog
+	case "event_frequency":
+		return h.Window.Events(e.IssueID, rule.Window(), now) > rule.Threshold
 
-	result := h.DB.Where("rule_id = ? AND issue_id = ?", rule.ID, e.IssueID).First(&existing)
-	if result.Error == nil && rule.Condition != "count_threshold" {
-		return
-	}
+	case "distinct_issues":
+		return h.Window.DistinctIssues(e.ProjectID, rule.Window(), now) > rule.Threshold
 
-	alertLog := models.AlertLog{
-		RuleID:    rule.ID,
-		IssueID:   e.IssueID,
-		ProjectID: e.ProjectID,
-		Message:   buildMessage(rule, e),
-		FiredAt:   time.Now(),
-	}
+	case "expression":
+		return h.matchExpression(rule, e)
 
-	result =  h.DB.Create(&alertLog)
-	if result.Error != nil{
-		log.Printf("Failed to save alert log: %v", result.Error)
-		return
+	default:
+		return false
 	}
-
-	log.Printf("ALERT FIRED [%s] %s", rule.Name, alertLog.Message)
 }
 
 func buildMessage(rule models.AlertRule, e models.IssueUpdateEvent) string {
@@ -164,6 +253,14 @@ func buildMessage(rule models.AlertRule, e models.IssueUpdateEvent) string {
 		return "Critical/error level issue detected: " + e.IssueID
 	case "count_threshold":
 		return fmt.Sprintf("Issue %s exceeded threshold of %d", e.IssueID, rule.Threshold)
+	case "regression":
+		return "Resolved issue regressed: " + e.IssueID
+	case "event_frequency":
+		return fmt.Sprintf("Issue %s had more than %d events in the last %d minutes", e.IssueID, rule.Threshold, rule.WindowMinutes)
+	case "distinct_issues":
+		return fmt.Sprintf("More than %d issues had events in project %s in the last %d minutes", rule.Threshold, e.ProjectID, rule.WindowMinutes)
+	case "expression":
+		return fmt.Sprintf("Issue %s matched %s", e.IssueID, rule.Expression)
 	default:
 		return "Alert triggered"
 	}
@@ -173,7 +270,7 @@ func (h *AlertHandler) GetProjectAlerts(c *gin.Context){
 	projectID := c.Param("project_id")
 
 	var alerts []models.AlertLog
-	result := h.DB.Where("project_id = ?", projectID).Order("fired_at desc").Limit(50).Find(&alerts)
+	result := h.DB.Preload("Deliveries").Where("project_id = ?", projectID).Order("fired_at desc").Limit(50).Find(&alerts)
 
 	if result.Error != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
@@ -201,7 +298,28 @@ func (h *AlertHandler) GetUnreadAlerts(c *gin.Context) {
 func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
 	alertID := c.Param("alert_id")
 
-	result := h.DB.Model(&models.AlertLog{}).Where("id = ?", alertID).Update("acknowledged", true)
+	var alert models.AlertLog
+	result := h.DB.Where("id = ?", alertID).First(&alert)
+	if result.Error != nil{
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+
+	role, err := auth.ProjectRole(h.DB, c.GetString("user_id"), alert.ProjectID)
+	if err != nil{
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify project access"})
+		return
+	}
+	if role == ""{
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+	if !auth.RoleAtLeast(role, auth.RoleMember){
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+		return
+	}
+
+	result = h.DB.Model(&alert).Update("acknowledged", true)
 
 	if result.Error != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to acknowledged alert"})
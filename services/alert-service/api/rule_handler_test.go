@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"gorm.io/gorm"
+)
+
+func postJSON(r *gin.Engine, path string, body any) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func countAlerts(db *gorm.DB, ruleID string) int64 {
+	var n int64
+	db.Model(&models.AlertLog{}).Where("rule_id = ?", ruleID).Count(&n)
+	return n
+}
+
+func TestCreateAlertRule_Windowed(t *testing.T) {
+	_, r := setupHandler(setupTestDB(t), nil)
+	path := "/projects/" + testProjectID + "/rules"
+
+	cases := []struct {
+		body map[string]any
+		code int
+	}{
+		{map[string]any{"name": "burst", "condition": "event_frequency", "threshold": 100, "window_minutes": 5}, http.StatusCreated},
+		{map[string]any{"name": "burst", "condition": "event_frequency", "threshold": 100}, http.StatusBadRequest},
+		{map[string]any{"name": "wide", "condition": "distinct_issues", "threshold": 10, "window_minutes": 1441}, http.StatusBadRequest},
+		{map[string]any{"name": "wide", "condition": "distinct_issues", "window_minutes": 10}, http.StatusBadRequest},
+	}
+	for i, tc := range cases {
+		if w := postJSON(r, path, tc.body); w.Code != tc.code {
+			t.Errorf("case %d: expected %d, got %d — body: %s", i, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestProcessAlert_EventFrequency(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, nil)
+	rule := models.AlertRule{ProjectID: testProjectID, Name: "burst", Condition: "event_frequency", Threshold: 3, WindowMinutes: 5, IsActive: true}
+	db.Create(&rule)
+
+	for count := 1; count <= 6; count++ {
+		h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: count, Status: "open"})
+	}
+	if n := countAlerts(db, rule.ID); n != 1 {
+		t.Errorf("expected one alert when the window crossed the threshold, got %d", n)
+	}
+
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-2", ProjectID: testProjectID, Count: 500, Status: "open"})
+	if n := countAlerts(db, rule.ID); n != 1 {
+		t.Errorf("expected the lifetime count of a newly seen issue not to count as a burst, got %d alerts", n)
+	}
+}
+
+func TestProcessAlert_DistinctIssues(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, nil)
+	rule := models.AlertRule{ProjectID: testProjectID, Name: "many", Condition: "distinct_issues", Threshold: 2, WindowMinutes: 10, IsActive: true}
+	db.Create(&rule)
+
+	for i := 1; i <= 4; i++ {
+		issueID := fmt.Sprintf("issue-%d", i)
+		h.ProcessAlert(models.IssueUpdateEvent{IssueID: issueID, ProjectID: testProjectID, Count: 1, Status: "open"})
+		h.ProcessAlert(models.IssueUpdateEvent{IssueID: issueID, ProjectID: testProjectID, Count: 2, Status: "open"})
+	}
+
+	var alerts []models.AlertLog
+	db.Where("rule_id = ?", rule.ID).Find(&alerts)
+	if len(alerts) != 1 || alerts[0].IssueID != "issue-3" {
+		t.Errorf("expected one alert raised by the third issue, got %+v", alerts)
+	}
+}
+
+func TestProcessAlert_Regression(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, nil)
+	rule := models.AlertRule{ProjectID: testProjectID, Name: "regressed", Condition: "regression", IsActive: true}
+	db.Create(&rule)
+
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 3, Status: "open", PreviousStatus: "resolved"})
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-2", ProjectID: testProjectID, Count: 4, Status: "regressed"})
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-2", ProjectID: testProjectID, Count: 5, Status: "open", PreviousStatus: "snoozed"})
+	if n := countAlerts(db, rule.ID); n != 0 {
+		t.Fatalf("expected no alerts without a resolved to regressed transition, got %d", n)
+	}
+
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-3", ProjectID: testProjectID, Count: 7, Status: "regressed", PreviousStatus: "resolved"})
+
+	var alerts []models.AlertLog
+	db.Where("rule_id = ?", rule.ID).Find(&alerts)
+	if len(alerts) != 1 || alerts[0].IssueID != "issue-3" {
+		t.Errorf("expected one alert for the regressed issue, got %+v", alerts)
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/services/alert-service/notifier"
+	"gorm.io/gorm"
+)
+
+// stateKey is the issue a rule's state is tracked for. distinct_issues is
+// about the whole project, so it has a single state per rule.
+func stateKey(rule models.AlertRule, issueID string) string {
+	if rule.Condition == "distinct_issues" {
+		return ""
+	}
+	return issueID
+}
+
+// Alert is called when rule's condition holds for the update. A rule that is
+// not firing starts firing and notifies its channels. A firing rule only
+// notifies again once its cooldown has passed, and never when the cooldown is
+// zero.
+func (h *AlertHandler) Alert(rule models.AlertRule, e models.IssueUpdateEvent, now time.Time) error {
+	key := stateKey(rule, e.IssueID)
+
+	var state models.AlertState
+	result := h.DB.Where("rule_id = ? AND issue_id = ?", rule.ID, key).First(&state)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to load alert state: %w", result.Error)
+	}
+
+	if result.Error == nil && state.State == models.StateFiring {
+		return h.stillFiring(rule, state, now)
+	}
+
+	alertLog := models.AlertLog{
+		RuleID:    rule.ID,
+		IssueID:   e.IssueID,
+		ProjectID: e.ProjectID,
+		Message:   buildMessage(rule, e),
+		FiredAt:   now,
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&alertLog).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Save(&models.AlertState{
+			RuleID:         rule.ID,
+			IssueID:        key,
+			ProjectID:      e.ProjectID,
+			State:          models.StateFiring,
+			AlertID:        alertLog.ID,
+			FiringSince:    &now,
+			LastTrueAt:     &now,
+			LastNotifiedAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save alert log: %w", err)
+	}
+
+	log.Printf("ALERT FIRED [%s] %s", rule.Name, alertLog.Message)
+	h.notify(rule, alertLog, notifier.EventAlertFired)
+	return nil
+}
+
+func (h *AlertHandler) stillFiring(rule models.AlertRule, state models.AlertState, now time.Time) error {
+	updates := map[string]interface{}{"last_true_at": now}
+
+	renotify := rule.CooldownMinutes > 0 && state.LastNotifiedAt != nil && now.Sub(*state.LastNotifiedAt) >= rule.Cooldown()
+	if renotify {
+		updates["last_notified_at"] = now
+	}
+
+	result := h.DB.Model(&models.AlertState{}).
+		Where("rule_id = ? AND issue_id = ? AND state = ?", state.RuleID, state.IssueID, models.StateFiring).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update alert state: %w", result.Error)
+	}
+
+	if renotify && result.RowsAffected == 1 {
+		var alertLog models.AlertLog
+		err := h.DB.Where("id = ?", state.AlertID).First(&alertLog).Error
+		if err != nil {
+			return fmt.Errorf("failed to load alert: %w", err)
+		}
+		h.notify(rule, alertLog, notifier.EventAlertFired)
+	}
+
+	return nil
+}
+
+// resolveIssue resolves every alert firing for an issue that was resolved.
+func (h *AlertHandler) resolveIssue(issueID string, now time.Time) error {
+	var states []models.AlertState
+	result := h.DB.Where("issue_id = ? AND state = ?", issueID, models.StateFiring).Find(&states)
+	if result.Error != nil {
+		return fmt.Errorf("failed to load alert states: %w", result.Error)
+	}
+
+	for _, state := range states {
+		err := h.resolve(state, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResolveStale resolves firing alerts whose condition has not held for the
+// rule's resolve_after_minutes.
+func (h *AlertHandler) ResolveStale(now time.Time) error {
+	var states []models.AlertState
+	result := h.DB.Joins("JOIN alert_rules ON alert_rules.id = alert_states.rule_id").
+		Where("alert_states.state = ? AND alert_rules.resolve_after_minutes > 0", models.StateFiring).
+		Find(&states)
+	if result.Error != nil {
+		return fmt.Errorf("failed to load alert states: %w", result.Error)
+	}
+
+	rules := map[string]models.AlertRule{}
+	for _, state := range states {
+		rule, ok := rules[state.RuleID]
+		if !ok {
+			err := h.DB.Where("id = ?", state.RuleID).First(&rule).Error
+			if err != nil {
+				return fmt.Errorf("failed to load alert rule: %w", err)
+			}
+			rules[state.RuleID] = rule
+		}
+
+		if state.LastTrueAt == nil || now.Sub(*state.LastTrueAt) < rule.ResolveAfter() {
+			continue
+		}
+
+		err := h.resolve(state, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *AlertHandler) RunResolver(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := h.ResolveStale(time.Now())
+		if err != nil {
+			log.Printf("Failed to resolve stale alerts: %v", err)
+		}
+	}
+}
+
+// resolve moves a firing state to resolved and sends the resolved
+// notification. The conditional update makes sure only one caller does so.
+func (h *AlertHandler) resolve(state models.AlertState, now time.Time) error {
+	var resolved bool
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AlertState{}).
+			Where("rule_id = ? AND issue_id = ? AND state = ?", state.RuleID, state.IssueID, models.StateFiring).
+			Updates(map[string]interface{}{"state": models.StateResolved, "resolved_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		resolved = result.RowsAffected == 1
+		if !resolved {
+			return nil
+		}
+
+		return tx.Model(&models.AlertLog{}).Where("id = ?", state.AlertID).Update("resolved_at", now).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve alert: %w", err)
+	}
+	if !resolved {
+		return nil
+	}
+
+	var rule models.AlertRule
+	var alertLog models.AlertLog
+	err = h.DB.Where("id = ?", state.RuleID).First(&rule).Error
+	if err == nil {
+		err = h.DB.Where("id = ?", state.AlertID).First(&alertLog).Error
+	}
+	if err != nil {
+		log.Printf("Resolved alert %s but could not notify: %v", state.AlertID, err)
+		return nil
+	}
+
+	log.Printf("ALERT RESOLVED [%s] %s", rule.Name, alertLog.Message)
+	h.notify(rule, alertLog, notifier.EventAlertResolved)
+	return nil
+}
+
+// GetAlertStates lists the project's alert states, firing ones by default.
+func (h *AlertHandler) GetAlertStates(c *gin.Context) {
+	projectID := c.Param("project_id")
+	state := c.DefaultQuery("state", models.StateFiring)
+
+	query := h.DB.Where("project_id = ?", projectID)
+	if state != "all" {
+		query = query.Where("state = ?", state)
+	}
+
+	var states []models.AlertState
+	result := query.Order("updated_at desc").Limit(100).Find(&states)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert states"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"states": states})
+}
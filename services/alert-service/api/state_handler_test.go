@@ -0,0 +1,100 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/services/alert-service/notifier"
+	"gorm.io/gorm"
+)
+
+func deliveryEvents(db *gorm.DB) []string {
+	var events []string
+	db.Model(&models.Delivery{}).Order("created_at").Pluck("event", &events)
+	return events
+}
+
+func loadState(t *testing.T, db *gorm.DB, ruleID string, issueID string) models.AlertState {
+	t.Helper()
+	var state models.AlertState
+	if err := db.Where("rule_id = ? AND issue_id = ?", ruleID, issueID).First(&state).Error; err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	return state
+}
+
+func TestAlertState_CooldownAndAutoResolve(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, &recordingQueue{})
+	rule := createRule(t, db, "count_threshold", "https://example.com/hook")
+	db.Model(&rule).Updates(map[string]interface{}{"threshold": 2, "cooldown_minutes": 10, "resolve_after_minutes": 30})
+	db.First(&rule, "id = ?", rule.ID)
+
+	for count := 3; count <= 6; count++ {
+		h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: count, Status: "open"})
+	}
+	if n := countAlerts(db, rule.ID); n != 1 {
+		t.Fatalf("expected one alert while firing, got %d", n)
+	}
+	state := loadState(t, db, rule.ID, "issue-1")
+	if state.State != models.StateFiring {
+		t.Fatalf("expected firing state, got %s", state.State)
+	}
+
+	now := time.Now()
+	e := models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 7, Status: "open"}
+	h.Alert(rule, e, now.Add(5*time.Minute))
+	h.Alert(rule, e, now.Add(11*time.Minute))
+	if events := deliveryEvents(db); len(events) != 2 || events[1] != notifier.EventAlertFired {
+		t.Fatalf("expected one re-notification after the cooldown, got %v", events)
+	}
+	if n := countAlerts(db, rule.ID); n != 1 {
+		t.Errorf("expected re-notification to reuse the alert, got %d alerts", n)
+	}
+
+	h.ResolveStale(now.Add(30 * time.Minute))
+	if state := loadState(t, db, rule.ID, "issue-1"); state.State != models.StateFiring {
+		t.Fatalf("expected state to keep firing within resolve_after_minutes, got %s", state.State)
+	}
+
+	h.ResolveStale(now.Add(42 * time.Minute))
+	if state := loadState(t, db, rule.ID, "issue-1"); state.State != models.StateResolved || state.ResolvedAt == nil {
+		t.Fatalf("expected resolved state, got %+v", state)
+	}
+	var alert models.AlertLog
+	db.Where("rule_id = ?", rule.ID).First(&alert)
+	if alert.ResolvedAt == nil {
+		t.Error("expected the alert to record when it resolved")
+	}
+	if events := deliveryEvents(db); len(events) != 3 || events[2] != notifier.EventAlertResolved {
+		t.Fatalf("expected a resolved notification, got %v", events)
+	}
+
+	h.ResolveStale(now.Add(50 * time.Minute))
+	if events := deliveryEvents(db); len(events) != 3 {
+		t.Errorf("expected a resolved alert to notify once, got %v", events)
+	}
+
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 8, Status: "open"})
+	if n := countAlerts(db, rule.ID); n != 2 {
+		t.Errorf("expected a new alert after resolution, got %d", n)
+	}
+}
+
+func TestAlertState_IssueResolved(t *testing.T) {
+	db := setupTestDB(t)
+	h, _ := setupHandler(db, &recordingQueue{})
+	rule := createRule(t, db, "critical_error", "https://example.com/hook")
+
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 1, Level: "critical", Status: "open"})
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 2, Level: "critical", Status: "open"})
+	h.ProcessAlert(models.IssueUpdateEvent{IssueID: "issue-1", ProjectID: testProjectID, Count: 2, Level: "critical", Status: "resolved", PreviousStatus: "open"})
+
+	if state := loadState(t, db, rule.ID, "issue-1"); state.State != models.StateResolved {
+		t.Fatalf("expected resolving the issue to resolve the alert, got %s", state.State)
+	}
+	if events := deliveryEvents(db); len(events) != 2 || events[1] != notifier.EventAlertResolved {
+		t.Errorf("expected fired and resolved notifications, got %v", events)
+	}
+}
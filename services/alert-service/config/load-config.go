@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,15 +12,51 @@ import (
 type Config struct {
 	DB PostgresConfig
 	TOKEN TokenConfig
+	CORS CorsConfig
+	ADMIN AdminConfig
 	KAFKA KafkaConfig
+	MAIL MailConfig
+	NOTIFY NotifyConfig
+	RABBITMQ RabbitConfig
+}
+
+type RabbitConfig struct{
+	User string
+	Password string
+	Host string
+	Port string
+}
+
+type MailConfig struct{
+	SmtpHost string
+	SmtpPort string
+	SmtpUsername string
+	SmtpPassword string
+	From string
+}
+
+type NotifyConfig struct{
+	Timeout time.Duration
+	Workers int
+	MaxAttempts int
+	RetryBaseDelay time.Duration
+	AllowPrivateTargets bool
 }
 
 type KafkaConfig struct{
 	Brokers []string
 }
 
+type AdminConfig struct{
+	UserIDs []string
+}
+
+type CorsConfig struct{
+	AllowedOrigins []string
+}
+
 type TokenConfig struct{
-	JwtKey string
+	JwksUrl string
 }
 
 type PostgresConfig struct {
@@ -47,12 +85,88 @@ func LoadConfig() (*Config, error) {
 		},
 
 		TOKEN: TokenConfig{
-			JwtKey: os.Getenv("JwtKey"),
+			JwksUrl: "http://localhost:8080/.well-known/jwks.json",
+		},
+
+		CORS: CorsConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
 		},
 
 		KAFKA: KafkaConfig{
 			Brokers: strings.Split(os.Getenv("KAFKA_BROKERS"), ","),
 		},
+
+		MAIL: MailConfig{
+			SmtpHost: os.Getenv("SMTP_HOST"),
+			SmtpPort: "587",
+			SmtpUsername: os.Getenv("SMTP_USERNAME"),
+			SmtpPassword: os.Getenv("SMTP_PASSWORD"),
+			From: "Atlas Alerts <alerts@atlas.local>",
+		},
+
+		NOTIFY: NotifyConfig{
+			Timeout: 10 * time.Second,
+			Workers: 4,
+			MaxAttempts: 5,
+			RetryBaseDelay: 30 * time.Second,
+		},
+
+		RABBITMQ: RabbitConfig{
+			User: os.Getenv("RABBIT_USER"),
+			Password: os.Getenv("RABBIT_PASSWORD"),
+			Host: os.Getenv("RABBIT_HOST"),
+			Port: os.Getenv("RABBIT_PORT"),
+		},
+	}
+
+	jwksUrl := os.Getenv("JWKS_URL")
+	if jwksUrl != ""{
+		config.TOKEN.JwksUrl = jwksUrl
+	}
+
+	admins := os.Getenv("ADMIN_USER_IDS")
+	if admins != ""{
+		config.ADMIN.UserIDs = strings.Split(admins, ",")
+	}
+
+	origins := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if origins != ""{
+		config.CORS.AllowedOrigins = strings.Split(origins, ",")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort != ""{
+		config.MAIL.SmtpPort = smtpPort
+	}
+
+	from := os.Getenv("MAIL_FROM")
+	if from != ""{
+		config.MAIL.From = from
+	}
+
+	timeout, err := strconv.Atoi(os.Getenv("NOTIFY_TIMEOUT_SECONDS"))
+	if err == nil && timeout > 0{
+		config.NOTIFY.Timeout = time.Duration(timeout) * time.Second
+	}
+
+	workers, err := strconv.Atoi(os.Getenv("NOTIFY_WORKERS"))
+	if err == nil && workers > 0{
+		config.NOTIFY.Workers = workers
+	}
+
+	maxAttempts, err := strconv.Atoi(os.Getenv("NOTIFY_MAX_ATTEMPTS"))
+	if err == nil && maxAttempts > 0{
+		config.NOTIFY.MaxAttempts = maxAttempts
+	}
+
+	retryBase, err := strconv.Atoi(os.Getenv("NOTIFY_RETRY_BASE_SECONDS"))
+	if err == nil && retryBase > 0{
+		config.NOTIFY.RetryBaseDelay = time.Duration(retryBase) * time.Second
+	}
+
+	allowPrivate, err := strconv.ParseBool(os.Getenv("NOTIFY_ALLOW_PRIVATE_TARGETS"))
+	if err == nil{
+		config.NOTIFY.AllowPrivateTargets = allowPrivate
 	}
 
 	return config, nil
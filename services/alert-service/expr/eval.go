@@ -0,0 +1,93 @@
+package expr
+
+type node interface {
+	eval(env Env) bool
+}
+
+type value struct {
+	str string
+	num float64
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(env Env) bool {
+	return n.left.eval(env) && n.right.eval(env)
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(env Env) bool {
+	return n.left.eval(env) || n.right.eval(env)
+}
+
+type notNode struct {
+	x node
+}
+
+func (n notNode) eval(env Env) bool {
+	return !n.x.eval(env)
+}
+
+type comparison struct {
+	field string
+	op    string
+	value value
+}
+
+func (n comparison) eval(env Env) bool {
+	kind, _ := fieldKind(n.field)
+	if kind == String {
+		equal := env.String(n.field) == n.value.str
+		if n.op == "!=" {
+			return !equal
+		}
+		return equal
+	}
+
+	x := env.Number(n.field)
+	switch n.op {
+	case "==":
+		return x == n.value.num
+	case "!=":
+		return x != n.value.num
+	case "<":
+		return x < n.value.num
+	case "<=":
+		return x <= n.value.num
+	case ">":
+		return x > n.value.num
+	case ">=":
+		return x >= n.value.num
+	default:
+		return false
+	}
+}
+
+type inList struct {
+	field  string
+	values []value
+	negate bool
+}
+
+func (n inList) eval(env Env) bool {
+	kind, _ := fieldKind(n.field)
+
+	found := false
+	for _, v := range n.values {
+		if kind == String {
+			found = env.String(n.field) == v.str
+		} else {
+			found = env.Number(n.field) == v.num
+		}
+		if found {
+			break
+		}
+	}
+
+	return found != n.negate
+}
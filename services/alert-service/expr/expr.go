@@ -0,0 +1,125 @@
+// Package expr compiles and evaluates alert rule expressions such as
+//
+//	level == critical and count > 20 and tags.environment in (production, staging)
+//
+// An expression compares issue fields with literal values and combines the
+// comparisons with and, or, not and parentheses. There are no function calls,
+// loops or field-to-field comparisons, and expressions are limited in length
+// and nesting, so evaluating one is cheap and always terminates.
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	MaxLength = 1024
+	MaxDepth  = 32
+)
+
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+)
+
+func (k Kind) String() string {
+	if k == Number {
+		return "number"
+	}
+	return "string"
+}
+
+// TagPrefix selects an event tag, e.g. tags.environment. A missing tag is the
+// empty string.
+const TagPrefix = "tags."
+
+// Fields are the issue update fields an expression can use, besides tags.
+var Fields = map[string]Kind{
+	"issue_id":        String,
+	"level":           String,
+	"status":          String,
+	"previous_status": String,
+	"count":           Number,
+}
+
+// Env supplies field values while a program runs.
+type Env interface {
+	String(field string) string
+	Number(field string) float64
+}
+
+// Error is a compile error. Pos is the 1-based character position it refers
+// to.
+type Error struct {
+	Pos int    `json:"position"`
+	Msg string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Program is a compiled expression. It is safe for concurrent use.
+type Program struct {
+	root node
+}
+
+// Compile parses and type checks src. Errors are *Error.
+func Compile(src string) (*Program, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, &Error{Pos: 1, Msg: "expression is empty"}
+	}
+	if len(src) > MaxLength {
+		return nil, &Error{Pos: MaxLength + 1, Msg: fmt.Sprintf("expression is longer than %d characters", MaxLength)}
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		if t.kind == tokRParen {
+			return nil, errorf(t.pos, `unexpected ")" without a matching "("`)
+		}
+		return nil, errorf(t.pos, "expected and, or or end of expression, got %s", t)
+	}
+
+	return &Program{root: root}, nil
+}
+
+// Match reports whether the expression holds for env.
+func (p *Program) Match(env Env) bool {
+	return p.root.eval(env)
+}
+
+func fieldKind(name string) (Kind, bool) {
+	if strings.HasPrefix(name, TagPrefix) {
+		return String, len(name) > len(TagPrefix)
+	}
+	kind, ok := Fields[name]
+	return kind, ok
+}
+
+func fieldNames() string {
+	names := make([]string, 0, len(Fields)+1)
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(append(names, TagPrefix+"<key>"), ", ")
+}
@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testEnv struct {
+	strings map[string]string
+	numbers map[string]float64
+}
+
+func (e testEnv) String(field string) string  { return e.strings[field] }
+func (e testEnv) Number(field string) float64 { return e.numbers[field] }
+
+func TestProgram_Match(t *testing.T) {
+	env := testEnv{
+		strings: map[string]string{
+			"level":            "critical",
+			"status":           "regressed",
+			"previous_status":  "resolved",
+			"tags.environment": "production",
+			"tags.release":     "1.4.2",
+		},
+		numbers: map[string]float64{"count": 25},
+	}
+
+	cases := []struct {
+		src  string
+		want bool
+	}{
+		{`level == critical and tags.environment == production and count > 20`, true},
+		{`level == "critical" AND count > 30`, false},
+		{`level == warning or count >= 25`, true},
+		{`level == warning || count < 25`, false},
+		{`not (level == critical)`, false},
+		{`!(status == open) && previous_status == 'resolved'`, true},
+		{`level in (error, critical)`, true},
+		{`level not in (error, critical)`, false},
+		{`count in (1, 25)`, true},
+		{`count != 25`, false},
+		{`count <= -1`, false},
+		{`tags.release == 1.4.2`, true},
+		{`tags.missing == ""`, true},
+		{`level == warning or level == critical and count > 100`, false},
+		{`(level == warning or level == critical) and count > 10`, true},
+		{`status == "say \"hi\""`, false},
+	}
+
+	for _, tc := range cases {
+		p, err := Compile(tc.src)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.src, err)
+			continue
+		}
+		if got := p.Match(env); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.src, tc.want, got)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	cases := []struct {
+		src  string
+		pos  int
+		want string
+	}{
+		{``, 1, "expression is empty"},
+		{`lvl == critical`, 1, `unknown field "lvl"`},
+		{`LEVEL == critical`, 1, `unknown field "LEVEL"`},
+		{`level = critical`, 7, `use "==" to compare`},
+		{`level > critical`, 7, "level is a string"},
+		{`count > high`, 9, `count is a number, got "high"`},
+		{`level == critical and`, 22, "expected a field name, got end of expression"},
+		{`(level == critical`, 19, `expected ")" to close the "(" at position 1`},
+		{`level == critical)`, 18, `unexpected ")"`},
+		{`level critical`, 7, "expected an operator"},
+		{`level in (error critical)`, 17, `expected "," or ")"`},
+		{`level == "critical`, 10, "unterminated string"},
+		{`count > 1 & level == error`, 11, `did you mean "&&"`},
+		{`tags. == x`, 1, `unknown field "tags."`},
+		{`count > 1 level == error`, 11, "expected and, or or end of expression"},
+		{strings.Repeat("(", MaxDepth+1) + "count > 1" + strings.Repeat(")", MaxDepth+1), MaxDepth + 1, "nested more than"},
+		{strings.Repeat("count > 1 or ", 100) + "count > 1", MaxLength + 1, "longer than"},
+	}
+
+	for _, tc := range cases {
+		_, err := Compile(tc.src)
+		var compileErr *Error
+		if !errors.As(err, &compileErr) {
+			t.Errorf("%.40s: expected a compile error, got %v", tc.src, err)
+			continue
+		}
+		if compileErr.Pos != tc.pos || !strings.Contains(compileErr.Msg, tc.want) {
+			t.Errorf("%.40s: expected %q at position %d, got %q at position %d", tc.src, tc.want, tc.pos, compileErr.Msg, compileErr.Pos)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package expr
+
+import (
+	"strconv"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr(depth int) (node, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (node, error) {
+	left, err := p.parseNot(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot(depth int) (node, error) {
+	t := p.peek()
+	if depth >= MaxDepth {
+		return nil, errorf(t.pos, "expression is nested more than %d levels deep", MaxDepth)
+	}
+
+	switch t.kind {
+	case tokNot:
+		p.next()
+		x, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+
+	case tokLParen:
+		p.next()
+		x, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, errorf(closing.pos, `expected ")" to close the "(" at position %d, got %s`, t.pos+1, closing)
+		}
+		return x, nil
+
+	default:
+		return p.parseComparison()
+	}
+}
+
+// parseComparison parses field op value, field in (values) and
+// field not in (values).
+func (p *parser) parseComparison() (node, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return nil, errorf(t.pos, "expected a field name, got %s", t)
+	}
+
+	kind, ok := fieldKind(t.text)
+	if !ok {
+		return nil, errorf(t.pos, "unknown field %q, expected one of %s", t.text, fieldNames())
+	}
+
+	op := p.next()
+	switch op.kind {
+	case tokOp:
+		if kind == String && op.text != "==" && op.text != "!=" {
+			return nil, errorf(op.pos, "%s is a string and can only be compared with == or !=", t.text)
+		}
+		v, err := p.parseValue(t.text, kind)
+		if err != nil {
+			return nil, err
+		}
+		return comparison{field: t.text, op: op.text, value: v}, nil
+
+	case tokIn:
+		return p.parseList(t.text, kind, false)
+
+	case tokNot:
+		if in := p.next(); in.kind != tokIn {
+			return nil, errorf(in.pos, `expected "in" after "not", got %s`, in)
+		}
+		return p.parseList(t.text, kind, true)
+
+	default:
+		return nil, errorf(op.pos, "expected an operator (==, !=, <, <=, >, >=, in) after %s, got %s", t.text, op)
+	}
+}
+
+func (p *parser) parseList(field string, kind Kind, negate bool) (node, error) {
+	open := p.next()
+	if open.kind != tokLParen {
+		return nil, errorf(open.pos, `expected "(" to start the list for %s, got %s`, field, open)
+	}
+
+	list := inList{field: field, negate: negate}
+	for {
+		v, err := p.parseValue(field, kind)
+		if err != nil {
+			return nil, err
+		}
+		list.values = append(list.values, v)
+
+		t := p.next()
+		if t.kind == tokRParen {
+			return list, nil
+		}
+		if t.kind != tokComma {
+			return nil, errorf(t.pos, `expected "," or ")" in the list for %s, got %s`, field, t)
+		}
+	}
+}
+
+// parseValue reads a literal for field. Strings may be quoted or bare words,
+// so level == critical and level == "critical" are the same.
+func (p *parser) parseValue(field string, kind Kind) (value, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokNumber:
+		if kind == String {
+			return value{str: t.text}, nil
+		}
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return value{}, errorf(t.pos, "invalid number %s", t)
+		}
+		return value{num: n}, nil
+
+	case tokString, tokIdent:
+		if kind == Number {
+			return value{}, errorf(t.pos, "%s is a number, got %s", field, t)
+		}
+		return value{str: t.text}, nil
+
+	default:
+		return value{}, errorf(t.pos, "expected a value for %s, got %s", field, t)
+	}
+}
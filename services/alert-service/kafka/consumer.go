@@ -1,40 +1,23 @@
 package kafka
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 
 	"github.com/k1ngalph0x/atlas/services/alert-service/api"
 	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/shared/dlq"
 	"github.com/segmentio/kafka-go"
 )
 
-func Consume(handler *api.AlertHandler){
-		reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: handler.Config.KAFKA.Brokers,
-		// Topic: "atlas-events",
-		// GroupID: "issue-consumers",
-		Topic: "issue-updates",  
-		GroupID: "alert-consumers",  
-	})
-	defer reader.Close()
-
-	for 
-	{
-		msg, err := reader.ReadMessage(context.Background())
-		if err != nil{
-			log.Println("Kafka read error:", err)
-			continue
-		}	
-
+func NewConsumer(handler *api.AlertHandler) *dlq.Consumer {
+	return dlq.NewConsumer(handler.Config.KAFKA.Brokers, "issue-updates", "alert-consumers", "issue-updates.alert-consumers.dlq", handler.DB, func(msg kafka.Message) error {
 		var event models.IssueUpdateEvent
-				err = json.Unmarshal(msg.Value, &event)
-		if err != nil{
-			log.Println("Invalid event:", err)
-			continue
+		err := json.Unmarshal(msg.Value, &event)
+		if err != nil {
+			return dlq.Permanent(fmt.Errorf("invalid event: %w", err))
 		}
 
-		handler.ProcessAlert(event)
-	}
-}
\ No newline at end of file
+		return handler.ProcessAlert(event)
+	})
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/k1ngalph0x/atlas/services/alert-service/api"
@@ -9,6 +10,10 @@ import (
 	"github.com/k1ngalph0x/atlas/services/alert-service/db"
 	"github.com/k1ngalph0x/atlas/services/alert-service/kafka"
 	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+	"github.com/k1ngalph0x/atlas/services/alert-service/rabbitmq"
+	"github.com/k1ngalph0x/atlas/shared/auth"
+	"github.com/k1ngalph0x/atlas/shared/dlq"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
 )
 
 func main() {
@@ -31,31 +36,62 @@ func main() {
 		log.Fatalf("Failed to migrate alert log table: %v", err)
 	}
 
-	handler := api.NewAlertHandler(conn, cfg)
+	err = conn.AutoMigrate(&models.AlertChannel{}, &models.Delivery{})
+	if err != nil{
+		log.Fatalf("Failed to migrate notification tables: %v", err)
+	}
+
+	err = conn.AutoMigrate(&models.AlertState{})
+	if err != nil{
+		log.Fatalf("Failed to migrate alert state table: %v", err)
+	}
+
+	err = conn.AutoMigrate(&dlq.ReplayRecord{})
+	if err != nil{
+		log.Fatalf("Failed to migrate dead-letter replay table: %v", err)
+	}
+
+	deliveryQueue := rabbitmq.New(cfg)
+	handler := api.NewAlertHandler(conn, cfg, deliveryQueue)
 
-	go kafka.Consume(handler)
+	go deliveryQueue.Run(cfg.NOTIFY.Workers, handler.Deliver)
+	go handler.RunRequeue(30 * time.Second)
+
+	consumer := kafka.NewConsumer(handler)
+	go consumer.Run()
+	go handler.RunResolver(time.Minute)
 
 	router := gin.Default()
 
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
-
-	router.POST("/projects/:project_id/rules", handler.CreateAlertRule)
-	router.GET("/projects/:project_id/rules", handler.GetAlertRules)
-	router.DELETE("/projects/:project_id/rules/:rule_id", handler.DeleteAlertRule)
-	router.GET("/projects/:project_id/alerts", handler.GetProjectAlerts)
-	router.GET("/projects/:project_id/alerts/unread", handler.GetUnreadAlerts)
+	authMiddleware := auth.NewMiddleware(jwks.NewVerifier(cfg.TOKEN.JwksUrl), conn)
+
+	router.Use(auth.CORS(cfg.CORS.AllowedOrigins))
+	router.Use(authMiddleware.RequireAuth())
+
+	member := auth.RequireProjectRole(auth.RoleMember)
+	project := router.Group("/projects/:project_id", authMiddleware.RequireProjectAccess())
+	{
+		project.POST("/rules", member, handler.CreateAlertRule)
+		project.GET("/rules", handler.GetAlertRules)
+		project.POST("/rules/test", handler.TestExpression)
+		project.DELETE("/rules/:rule_id", member, handler.DeleteAlertRule)
+		project.GET("/rules/:rule_id/channels", handler.GetChannels)
+		project.POST("/rules/:rule_id/channels", member, handler.CreateChannel)
+		project.DELETE("/rules/:rule_id/channels/:channel_id", member, handler.DeleteChannel)
+		project.GET("/alerts", handler.GetProjectAlerts)
+		project.GET("/alerts/unread", handler.GetUnreadAlerts)
+		project.GET("/alert-states", handler.GetAlertStates)
+		project.GET("/deliveries", handler.GetDeliveries)
+		project.POST("/deliveries/:delivery_id/retry", member, handler.RetryDelivery)
+	}
+
 	router.POST("/alerts/:alert_id/acknowledge", handler.AcknowledgeAlert)
+
+	admin := router.Group("/admin", auth.RequireAdmin(cfg.ADMIN.UserIDs))
+	{
+		admin.GET("/dlq", consumer.ListMessages)
+		admin.POST("/dlq/:offset/replay", consumer.ReplayMessage)
+	}
+
 	router.Run(":8084")
 }
@@ -9,12 +9,14 @@ import (
 )
 
 type IssueUpdateEvent struct {
-	IssueID   string    `json:"issue_id"`
-	ProjectID string    `json:"project_id"`
-	Count     int       `json:"count"`
-	Level     string    `json:"level"`
-	Status    string    `json:"status"`
-	UpdatedAt time.Time `json:"updated_at"`
+	IssueID        string    `json:"issue_id"`
+	ProjectID      string    `json:"project_id"`
+	Count          int       `json:"count"`
+	Level          string    `json:"level"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type AlertRule struct {
@@ -23,7 +25,12 @@ type AlertRule struct {
 	Name        string    `gorm:"not null" json:"name"`
 	Condition   string    `gorm:"not null" json:"condition"`
 	Threshold   int       `gorm:"default:0" json:"threshold"`
+	WindowMinutes int     `gorm:"default:0" json:"window_minutes"`
+	CooldownMinutes int   `gorm:"default:0" json:"cooldown_minutes"`
+	ResolveAfterMinutes int `gorm:"default:0" json:"resolve_after_minutes"`
+	Expression  string    `gorm:"type:text" json:"expression,omitempty"`
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	Channels    []AlertChannel `gorm:"foreignKey:RuleID" json:"channels"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -36,6 +43,87 @@ type AlertLog struct {
 	Message   string    `gorm:"not null" json:"message"`
 	Acknowledged bool   `gorm:"default:false" json:"acknowledged"`
 	FiredAt   time.Time `gorm:"autoCreateTime" json:"fired_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	Deliveries []Delivery `gorm:"foreignKey:AlertLogID" json:"deliveries"`
+}
+
+const (
+	StateOK       = "ok"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// AlertState tracks a rule for one issue, or for the whole project when
+// IssueID is empty (distinct_issues). A rule fires when its state moves to
+// firing and stays quiet until the state is resolved.
+type AlertState struct {
+	RuleID         string     `gorm:"type:uuid;primaryKey" json:"rule_id"`
+	IssueID        string     `gorm:"type:varchar(64);primaryKey" json:"issue_id"`
+	ProjectID      string     `gorm:"type:uuid;not null;index" json:"project_id"`
+	State          string     `gorm:"type:varchar(16);not null;index" json:"state"`
+	AlertID        string     `gorm:"type:uuid" json:"alert_id"`
+	FiringSince    *time.Time `json:"firing_since"`
+	LastTrueAt     *time.Time `json:"last_true_at"`
+	LastNotifiedAt *time.Time `json:"last_notified_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+)
+
+// AlertChannel is where a rule's alerts are sent. Target is the URL for
+// webhook and slack channels and a comma-separated address list for email.
+type AlertChannel struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	RuleID    string    `gorm:"type:uuid;not null;index" json:"rule_id"`
+	Type      string    `gorm:"type:varchar(16);not null" json:"type"`
+	Target    string    `gorm:"not null" json:"target"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// SigningSecret is only set in the response that created a webhook
+	// channel, so the receiver can be configured to verify signatures.
+	SigningSecret string `gorm:"-" json:"signing_secret,omitempty"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Delivery records sending one alert to one channel.
+type Delivery struct {
+	ID            string     `gorm:"type:uuid;primaryKey" json:"id"`
+	AlertLogID    string     `gorm:"type:uuid;not null;index" json:"alert_log_id"`
+	ChannelID     string     `gorm:"type:uuid;not null;index" json:"channel_id"`
+	ChannelType   string     `gorm:"type:varchar(16);not null" json:"channel_type"`
+	Event         string     `gorm:"type:varchar(32);not null;default:'alert.fired'" json:"event"`
+	Status        string     `gorm:"type:varchar(16);not null;index" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	Error         string     `json:"error,omitempty"`
+	QueuedAt      *time.Time `gorm:"index" json:"queued_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Window is the time span for event_frequency and distinct_issues rules.
+func (a AlertRule) Window() time.Duration {
+	return time.Duration(a.WindowMinutes) * time.Minute
+}
+
+func (a AlertRule) Cooldown() time.Duration {
+	return time.Duration(a.CooldownMinutes) * time.Minute
+}
+
+func (a AlertRule) ResolveAfter() time.Duration {
+	return time.Duration(a.ResolveAfterMinutes) * time.Minute
 }
 
 func (a *AlertRule) BeforeCreate(tx *gorm.DB) error {
@@ -50,4 +138,18 @@ func (a *AlertLog) BeforeCreate(tx *gorm.DB) error {
 		a.ID = uuid.New().String()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (a *AlertChannel) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (d *Delivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
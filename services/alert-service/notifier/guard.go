@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var errBlockedTarget = errors.New("target resolves to a private, loopback or link-local address")
+
+var blockedNets = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),
+	mustCIDR("100.64.0.0/10"),
+	mustCIDR("192.0.0.0/24"),
+	mustCIDR("198.18.0.0/15"),
+}
+
+func mustCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// blockedIP reports whether ip is somewhere a notification must not be sent:
+// loopback, private, link-local (including cloud metadata endpoints) and
+// other non-public ranges.
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+
+	for _, ipNet := range blockedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURL checks an http(s) target. Unless allowPrivate is set, hosts that
+// are or resolve to a blocked address are rejected. A host that does not
+// resolve yet is accepted; the dialer checks again on every send.
+func validateURL(target string, allowPrivate bool) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("target must be an http or https URL")
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errBlockedTarget
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedIP(ip) {
+			return errBlockedTarget
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if blockedIP(addr.IP) {
+			return errBlockedTarget
+		}
+	}
+	return nil
+}
+
+// guardedDialer refuses connections to blocked addresses. It checks the
+// address actually dialed, so DNS rebinding and redirects to internal hosts
+// are caught too.
+func guardedDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || blockedIP(ip) {
+				return fmt.Errorf("refusing to connect to %s: %w", host, errBlockedTarget)
+			}
+			return nil
+		},
+	}
+}
+
+// newHTTPClient returns the client for webhook and Slack sends. Proxies from
+// the environment are ignored so the dialer sees the real destination.
+func newHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		transport.Proxy = nil
+		transport.DialContext = guardedDialer(timeout).DialContext
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/alert-service/config"
+	"github.com/k1ngalph0x/atlas/services/alert-service/models"
+)
+
+const (
+	EventAlertFired    = "alert.fired"
+	EventAlertResolved = "alert.resolved"
+)
+
+// Notification is the payload sent to every channel. Webhooks receive it as
+// JSON.
+type Notification struct {
+	Event     string    `json:"event"`
+	AlertID   string    `json:"alert_id"`
+	RuleID    string    `json:"rule_id"`
+	RuleName  string    `json:"rule_name"`
+	ProjectID string    `json:"project_id"`
+	IssueID   string    `json:"issue_id"`
+	Message   string    `json:"message"`
+	FiredAt   time.Time `json:"fired_at"`
+
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Notifier sends a notification to one channel. The returned code is the
+// receiver's HTTP or SMTP status, or 0 if it was never reached.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) (int, error)
+}
+
+// New returns the notifier for a channel.
+func New(channel models.AlertChannel, config *config.Config) (Notifier, error) {
+	client := newHTTPClient(config.NOTIFY.Timeout, config.NOTIFY.AllowPrivateTargets)
+
+	switch channel.Type {
+	case models.ChannelWebhook:
+		return &Webhook{URL: channel.Target, Secret: channel.Secret, Client: client}, nil
+
+	case models.ChannelSlack:
+		return &Slack{URL: channel.Target, Client: client}, nil
+
+	case models.ChannelEmail:
+		if config.MAIL.SmtpHost == "" {
+			return nil, errors.New("email channels need SMTP_HOST to be configured")
+		}
+		to, err := Recipients(channel.Target)
+		if err != nil {
+			return nil, err
+		}
+		return &Email{
+			Addr:     net.JoinHostPort(config.MAIL.SmtpHost, config.MAIL.SmtpPort),
+			Username: config.MAIL.SmtpUsername,
+			Password: config.MAIL.SmtpPassword,
+			From:     config.MAIL.From,
+			To:       to,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", channel.Type)
+	}
+}
+
+// ValidateTarget checks a channel's target before it is saved and returns
+// the target to store. URL targets must not point at internal hosts unless
+// allowPrivate is set.
+func ValidateTarget(channelType string, target string, allowPrivate bool) (string, error) {
+	switch channelType {
+	case models.ChannelWebhook, models.ChannelSlack:
+		return target, validateURL(target, allowPrivate)
+
+	case models.ChannelEmail:
+		to, err := Recipients(target)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(to, ", "), nil
+
+	default:
+		return "", fmt.Errorf("unknown channel type %q", channelType)
+	}
+}
+
+// Recipients parses an email target into bare addresses, so display names
+// with commas such as "Doe, Jane" <jane@example.com> survive.
+func Recipients(target string) ([]string, error) {
+	addresses, err := mail.ParseAddressList(target)
+	if err != nil || len(addresses) == 0 {
+		return nil, errors.New("target must be a comma-separated list of email addresses")
+	}
+
+	to := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		to = append(to, address.Address)
+	}
+	return to, nil
+}
+
+func subject(n Notification) string {
+	if n.Event == EventAlertResolved {
+		return fmt.Sprintf("[Atlas] Resolved: %s", n.RuleName)
+	}
+	return fmt.Sprintf("[Atlas] %s", n.RuleName)
+}
+
+func text(n Notification) string {
+	when := "Fired at: " + n.FiredAt.UTC().Format(time.RFC3339)
+	if n.Event == EventAlertResolved && n.ResolvedAt != nil {
+		when = "Resolved at: " + n.ResolvedAt.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("%s\n\nRule: %s\nProject: %s\nIssue: %s\n%s",
+		n.Message, n.RuleName, n.ProjectID, n.IssueID, when)
+}
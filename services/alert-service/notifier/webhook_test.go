@@ -0,0 +1,154 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Signed(t *testing.T) {
+	var got Notification
+	var signature, timestamp string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL, Secret: "whsec_test", Client: server.Client()}
+	code, err := webhook.Notify(context.Background(), Notification{
+		Event:   EventAlertFired,
+		AlertID: "alert-1",
+		Message: "Issue exceeded threshold",
+		FiredAt: time.Now(),
+	})
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d %v", code, err)
+	}
+
+	if got.AlertID != "alert-1" || got.Event != EventAlertFired {
+		t.Errorf("unexpected payload %+v", got)
+	}
+	if signature != "sha256="+Sign("whsec_test", timestamp, body) {
+		t.Errorf("signature %q does not match the body", signature)
+	}
+	if signature == "sha256="+Sign("other", timestamp, body) {
+		t.Error("expected signature to depend on the secret")
+	}
+}
+
+func TestWebhook_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL, Client: server.Client()}
+	code, err := webhook.Notify(context.Background(), Notification{})
+	if err == nil || code != http.StatusBadGateway {
+		t.Fatalf("expected 502 error, got %d %v", code, err)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	slack := &Slack{URL: server.URL, Client: server.Client()}
+	_, err := slack.Notify(context.Background(), Notification{RuleName: "Errors", Message: "Critical issue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(payload["text"], "[Atlas] Errors") || !strings.Contains(payload["text"], "Critical issue") {
+		t.Errorf("unexpected slack text %q", payload["text"])
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	cases := []struct {
+		channelType string
+		target      string
+		valid       bool
+	}{
+		{"webhook", "https://example.com/hooks/atlas", true},
+		{"webhook", "https://93.184.216.34/hooks/atlas", true},
+		{"webhook", "ftp://example.com", false},
+		{"slack", "not a url", false},
+		{"webhook", "http://localhost:8080/hook", false},
+		{"webhook", "http://127.0.0.1/hook", false},
+		{"webhook", "http://10.0.0.5/hook", false},
+		{"slack", "http://192.168.1.1/hook", false},
+		{"webhook", "http://169.254.169.254/latest/meta-data", false},
+		{"webhook", "http://[::1]:9000/hook", false},
+		{"webhook", "http://[fd00::1]/hook", false},
+		{"webhook", "http://0.0.0.0/hook", false},
+		{"email", "ops@example.com, Dev <dev@example.com>", true},
+		{"email", "ops", false},
+		{"sms", "+15550100", false},
+	}
+	for _, tc := range cases {
+		_, err := ValidateTarget(tc.channelType, tc.target, false)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s %q: expected valid=%v, got %v", tc.channelType, tc.target, tc.valid, err)
+		}
+	}
+
+	if _, err := ValidateTarget("webhook", "http://127.0.0.1/hook", true); err != nil {
+		t.Errorf("expected private targets to be allowed when configured, got %v", err)
+	}
+}
+
+func TestValidateTarget_EmailRecipients(t *testing.T) {
+	target, err := ValidateTarget("email", `"Doe, Jane" <jane@example.com>, ops@example.com`, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "jane@example.com, ops@example.com" {
+		t.Errorf("expected the parsed addresses to be stored, got %q", target)
+	}
+
+	to, err := Recipients(target)
+	if err != nil || len(to) != 2 || to[0] != "jane@example.com" || to[1] != "ops@example.com" {
+		t.Errorf("expected the stored target to parse back, got %v %v", to, err)
+	}
+}
+
+func TestGuardedClient_RefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	webhook := &Webhook{URL: server.URL, Client: newHTTPClient(time.Second, false)}
+	if _, err := webhook.Notify(context.Background(), Notification{}); err == nil || !strings.Contains(err.Error(), "refusing to connect") {
+		t.Errorf("expected the send to a loopback receiver to be refused, got %v", err)
+	}
+
+	webhook.Client = newHTTPClient(time.Second, true)
+	if code, err := webhook.Notify(context.Background(), Notification{}); err != nil || code != http.StatusOK {
+		t.Errorf("expected the send to succeed when private targets are allowed, got %d %v", code, err)
+	}
+}
+
+func TestText_Resolved(t *testing.T) {
+	fired := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	resolved := fired.Add(time.Hour)
+
+	body := text(Notification{Event: EventAlertResolved, FiredAt: fired, ResolvedAt: &resolved})
+	if !strings.Contains(body, "Resolved at: 2024-01-01T11:00:00Z") || strings.Contains(body, "Fired at") {
+		t.Errorf("expected the resolved time, got %q", body)
+	}
+	if body := text(Notification{Event: EventAlertFired, FiredAt: fired}); !strings.Contains(body, "Fired at: 2024-01-01T10:00:00Z") {
+		t.Errorf("expected the fired time, got %q", body)
+	}
+}
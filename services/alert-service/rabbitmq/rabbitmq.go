@@ -0,0 +1,248 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/alert-service/config"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	DeliveryQueue = "alert-deliveries"
+
+	maxBackoff        = time.Hour
+	maxReconnectDelay = 30 * time.Second
+)
+
+var ErrNotConnected = errors.New("notification queue is not connected")
+
+type Job struct {
+	DeliveryID string `json:"delivery_id"`
+	Attempt    int    `json:"attempt"`
+}
+
+// Handler makes one attempt at a delivery. final marks the last attempt; an
+// error before that schedules a retry.
+type Handler func(deliveryID string, final bool) error
+
+// Queue delivers notifications through RabbitMQ. A failed job is parked in a
+// retry queue whose TTL is the backoff for that attempt; when it expires
+// RabbitMQ dead-letters it back onto DeliveryQueue. After MaxAttempts the job
+// is dropped, and the handler has already recorded the delivery as failed.
+//
+// Run keeps the connection up, reconnecting whenever it drops. While it is
+// down Enqueue returns ErrNotConnected.
+type Queue struct {
+	URL         string
+	MaxAttempts int
+	BaseDelay   time.Duration
+
+	mu      sync.RWMutex
+	channel *amqp.Channel
+}
+
+func New(cfg *config.Config) *Queue {
+	return &Queue{
+		URL: fmt.Sprintf("amqp://%s:%s@%s:%s/",
+			cfg.RABBITMQ.User,
+			cfg.RABBITMQ.Password,
+			cfg.RABBITMQ.Host,
+			cfg.RABBITMQ.Port,
+		),
+		MaxAttempts: cfg.NOTIFY.MaxAttempts,
+		BaseDelay:   cfg.NOTIFY.RetryBaseDelay,
+	}
+}
+
+// Run connects and consumes DeliveryQueue with numWorkers goroutines. It
+// reconnects with a growing delay when the connection fails or drops, and
+// never returns.
+func (q *Queue) Run(numWorkers int, handle Handler) {
+	delay := time.Second
+	for {
+		connected, err := q.session(numWorkers, handle)
+		if connected {
+			delay = time.Second
+		}
+		log.Printf("Notification queue disconnected, reconnecting in %s: %v", delay, err)
+
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// session runs one connection until it closes. connected reports whether it
+// got as far as consuming.
+func (q *Queue) session(numWorkers int, handle Handler) (bool, error) {
+	conn, err := amqp.Dial(q.URL)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	err = q.declare(ch)
+	if err != nil {
+		return false, err
+	}
+
+	err = ch.Qos(numWorkers, 0, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	var workers sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		msgs, err := ch.Consume(DeliveryQueue, "", false, false, false, false, nil)
+		if err != nil {
+			return false, fmt.Errorf("worker %d failed to consume: %w", i, err)
+		}
+		workers.Add(1)
+		go func(id int) {
+			defer workers.Done()
+			q.worker(id, ch, msgs, handle)
+		}(i)
+	}
+
+	q.setChannel(ch)
+	defer q.setChannel(nil)
+	log.Printf("Connected to RabbitMQ, started %d notification workers", numWorkers)
+
+	var closeErr *amqp.Error
+	select {
+	case closeErr = <-connClosed:
+	case closeErr = <-chClosed:
+	}
+
+	conn.Close()
+	workers.Wait()
+
+	if closeErr == nil {
+		return true, errors.New("connection closed")
+	}
+	return true, closeErr
+}
+
+func (q *Queue) setChannel(ch *amqp.Channel) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.channel = ch
+}
+
+func (q *Queue) declare(ch *amqp.Channel) error {
+	_, err := ch.QueueDeclare(DeliveryQueue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	for attempt := 1; attempt < q.MaxAttempts; attempt++ {
+		delay := q.Backoff(attempt)
+		_, err = ch.QueueDeclare(retryQueue(delay), true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": DeliveryQueue,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Backoff is how long to wait before the given retry (1 for the first).
+func (q *Queue) Backoff(attempt int) time.Duration {
+	delay := q.BaseDelay
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// Retry queues are named by their TTL, so changing the backoff settings
+// declares new queues instead of clashing with the old arguments.
+func retryQueue(delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%ds", DeliveryQueue, int(delay.Seconds()))
+}
+
+func (q *Queue) Enqueue(deliveryID string) error {
+	q.mu.RLock()
+	ch := q.channel
+	q.mu.RUnlock()
+
+	if ch == nil {
+		return ErrNotConnected
+	}
+	return publish(ch, DeliveryQueue, Job{DeliveryID: deliveryID})
+}
+
+func publish(ch *amqp.Channel, queue string, job Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return ch.PublishWithContext(
+		context.Background(),
+		"",
+		queue,
+		false,
+		false,
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         body,
+		},
+	)
+}
+
+// worker handles jobs until msgs closes, which happens when the connection
+// drops. Unacknowledged jobs go back on the queue and are redelivered after
+// the reconnect.
+func (q *Queue) worker(id int, ch *amqp.Channel, msgs <-chan amqp.Delivery, handle Handler) {
+	for msg := range msgs {
+		var job Job
+		err := json.Unmarshal(msg.Body, &job)
+		if err != nil {
+			log.Printf("Notification worker %d: invalid job: %v", id, err)
+			msg.Nack(false, false)
+			continue
+		}
+
+		final := job.Attempt+1 >= q.MaxAttempts
+		err = handle(job.DeliveryID, final)
+		if err == nil || final {
+			msg.Ack(false)
+			continue
+		}
+
+		job.Attempt++
+		err = publish(ch, retryQueue(q.Backoff(job.Attempt)), job)
+		if err != nil {
+			log.Printf("Notification worker %d: failed to reschedule delivery %s: %v", id, job.DeliveryID, err)
+			msg.Nack(false, true)
+			continue
+		}
+
+		msg.Ack(false)
+	}
+}
@@ -0,0 +1,27 @@
+package rabbitmq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	q := &Queue{BaseDelay: 30 * time.Second, MaxAttempts: 10}
+
+	cases := map[int]time.Duration{
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+		5: 8 * time.Minute,
+		9: time.Hour,
+	}
+	for attempt, want := range cases {
+		if got := q.Backoff(attempt); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+
+	if name := retryQueue(q.Backoff(2)); name != "alert-deliveries.retry.60s" {
+		t.Errorf("unexpected retry queue name %q", name)
+	}
+}
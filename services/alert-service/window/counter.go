@@ -0,0 +1,167 @@
+package window
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	Resolution = 10 * time.Second
+	Retention  = 24 * time.Hour
+
+	pruneInterval = time.Minute
+)
+
+// Counter keeps sliding-window event counts per issue and the set of issues
+// recently seen per project. Issue updates carry the issue's lifetime count,
+// so the number of new events is the difference from the previous update.
+//
+// Counts live in memory and start empty after a restart. issue-updates is
+// keyed by project, so each project's updates reach a single instance.
+type Counter struct {
+	mu        sync.Mutex
+	issues    map[string]*issueSeries
+	projects  map[string]map[string]*issueSeries
+	lastPrune time.Time
+}
+
+type issueSeries struct {
+	projectID string
+	count     int
+	lastEvent time.Time
+	buckets   []bucket
+}
+
+type bucket struct {
+	index int64
+	count int
+}
+
+// Observation is what one update added to the counter.
+type Observation struct {
+	// NewEvents is how many events the update represents.
+	NewEvents int
+
+	// PreviousEvent is when the issue last had new events, or zero if it has
+	// not been seen.
+	PreviousEvent time.Time
+}
+
+func NewCounter() *Counter {
+	return &Counter{
+		issues:   map[string]*issueSeries{},
+		projects: map[string]map[string]*issueSeries{},
+	}
+}
+
+// Observe records an issue update. countsAsEvent is false for updates that
+// only change the issue's status; they never add events for an issue seen for
+// the first time.
+func (c *Counter) Observe(projectID string, issueID string, count int, countsAsEvent bool, now time.Time) Observation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prune(now)
+
+	s, ok := c.issues[issueID]
+	if !ok {
+		s = &issueSeries{projectID: projectID, count: count}
+		c.issues[issueID] = s
+		if c.projects[projectID] == nil {
+			c.projects[projectID] = map[string]*issueSeries{}
+		}
+		c.projects[projectID][issueID] = s
+	}
+
+	obs := Observation{PreviousEvent: s.lastEvent}
+	switch {
+	case !ok && countsAsEvent:
+		obs.NewEvents = 1
+	case ok && count > s.count:
+		obs.NewEvents = count - s.count
+	}
+	if count > s.count {
+		s.count = count
+	}
+
+	if obs.NewEvents > 0 {
+		s.add(obs.NewEvents, now)
+		s.lastEvent = now
+	}
+
+	return obs
+}
+
+// Events is the number of events for the issue in the window ending at now.
+func (c *Counter) Events(issueID string, window time.Duration, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.issues[issueID]
+	if !ok {
+		return 0
+	}
+	return s.sum(window, now)
+}
+
+// DistinctIssues is the number of the project's issues with events in the
+// window ending at now.
+func (c *Counter) DistinctIssues(projectID string, window time.Duration, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	since := now.Add(-window)
+	total := 0
+	for _, s := range c.projects[projectID] {
+		if !s.lastEvent.IsZero() && s.lastEvent.After(since) {
+			total++
+		}
+	}
+	return total
+}
+
+func (s *issueSeries) add(n int, now time.Time) {
+	index := now.UnixNano() / int64(Resolution)
+	last := len(s.buckets) - 1
+	if last >= 0 && s.buckets[last].index == index {
+		s.buckets[last].count += n
+		return
+	}
+	s.buckets = append(s.buckets, bucket{index: index, count: n})
+}
+
+// sum counts whole buckets, so the window is accurate to Resolution.
+func (s *issueSeries) sum(window time.Duration, now time.Time) int {
+	first := now.Add(-window).UnixNano()/int64(Resolution) + 1
+	total := 0
+	for i := len(s.buckets) - 1; i >= 0 && s.buckets[i].index >= first; i-- {
+		total += s.buckets[i].count
+	}
+	return total
+}
+
+// prune drops buckets older than Retention and forgets issues with no events
+// in that time. It must be called with mu held.
+func (c *Counter) prune(now time.Time) {
+	if now.Sub(c.lastPrune) < pruneInterval {
+		return
+	}
+	c.lastPrune = now
+
+	oldest := now.Add(-Retention).UnixNano() / int64(Resolution)
+	for id, s := range c.issues {
+		drop := 0
+		for drop < len(s.buckets) && s.buckets[drop].index < oldest {
+			drop++
+		}
+		s.buckets = s.buckets[drop:]
+
+		if len(s.buckets) == 0 && now.Sub(s.lastEvent) > Retention {
+			delete(c.issues, id)
+			delete(c.projects[s.projectID], id)
+			if len(c.projects[s.projectID]) == 0 {
+				delete(c.projects, s.projectID)
+			}
+		}
+	}
+}
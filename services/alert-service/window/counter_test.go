@@ -0,0 +1,65 @@
+package window
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounter_Events(t *testing.T) {
+	c := NewCounter()
+	start := time.Now().Truncate(time.Minute)
+
+	if obs := c.Observe("p1", "i1", 1, true, start); obs.NewEvents != 1 || !obs.PreviousEvent.IsZero() {
+		t.Fatalf("expected a new issue to count one event, got %+v", obs)
+	}
+	if obs := c.Observe("p1", "i1", 5, true, start.Add(time.Minute)); obs.NewEvents != 4 || !obs.PreviousEvent.Equal(start) {
+		t.Fatalf("expected four new events, got %+v", obs)
+	}
+	if obs := c.Observe("p1", "i1", 5, false, start.Add(time.Minute)); obs.NewEvents != 0 {
+		t.Fatalf("expected a status change to add no events, got %+v", obs)
+	}
+
+	now := start.Add(2 * time.Minute)
+	if n := c.Events("i1", 5*time.Minute, now); n != 5 {
+		t.Errorf("expected 5 events in 5 minutes, got %d", n)
+	}
+	if n := c.Events("i1", 90*time.Second, now); n != 4 {
+		t.Errorf("expected the first event to have left a 90 second window, got %d", n)
+	}
+	if n := c.Events("i2", time.Hour, now); n != 0 {
+		t.Errorf("expected no events for an unknown issue, got %d", n)
+	}
+}
+
+func TestCounter_DistinctIssues(t *testing.T) {
+	c := NewCounter()
+	start := time.Now()
+
+	c.Observe("p1", "i1", 10, true, start)
+	c.Observe("p1", "i2", 1, true, start.Add(4*time.Minute))
+	c.Observe("p1", "i3", 3, false, start.Add(4*time.Minute))
+	c.Observe("p2", "i4", 1, true, start.Add(4*time.Minute))
+
+	now := start.Add(5 * time.Minute)
+	if n := c.DistinctIssues("p1", 10*time.Minute, now); n != 2 {
+		t.Errorf("expected 2 issues in 10 minutes, got %d", n)
+	}
+	if n := c.DistinctIssues("p1", 2*time.Minute, now); n != 1 {
+		t.Errorf("expected 1 issue in 2 minutes, got %d", n)
+	}
+}
+
+func TestCounter_Prune(t *testing.T) {
+	c := NewCounter()
+	start := time.Now()
+
+	c.Observe("p1", "i1", 1, true, start)
+	c.Observe("p1", "i2", 1, true, start.Add(Retention+2*time.Minute))
+
+	if _, ok := c.issues["i1"]; ok {
+		t.Error("expected an issue idle for longer than the retention to be pruned")
+	}
+	if len(c.projects["p1"]) != 1 {
+		t.Errorf("expected one tracked issue in p1, got %d", len(c.projects["p1"]))
+	}
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/mailer"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+var errTokenInvalid = errors.New("token is invalid or expired")
+
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// issueUserToken replaces any unused token of the same purpose, so only the
+// most recent email works.
+func (a *AuthHandler) issueUserToken(user models.User, purpose string, ttl time.Duration) (string, error) {
+	raw := randomToken("ut_")
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.UserID, purpose).
+			Delete(&models.UserToken{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserToken{
+			UserID:    user.UserID,
+			Purpose:   purpose,
+			TokenHash: sharedModels.HashAPIKey(raw),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+
+	return raw, err
+}
+
+// consumeUserToken marks the token used and runs apply in the same
+// transaction. It fails with errTokenInvalid for unknown, used or expired
+// tokens.
+func (a *AuthHandler) consumeUserToken(raw string, purpose string, apply func(tx *gorm.DB, userID string) error) error {
+	now := time.Now()
+
+	return a.DB.Transaction(func(tx *gorm.DB) error {
+		var token models.UserToken
+		err := tx.Where("token_hash = ? AND purpose = ?", sharedModels.HashAPIKey(raw), purpose).First(&token).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		if now.After(token.ExpiresAt) {
+			return errTokenInvalid
+		}
+
+		result := tx.Model(&models.UserToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTokenInvalid
+		}
+
+		return apply(tx, token.UserID)
+	})
+}
+
+func (a *AuthHandler) link(path string, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", a.Config.ACCOUNT.AppUrl, path, url.QueryEscape(token))
+}
+
+// sendVerification is best effort: a failed send is logged and the user can
+// ask for another email.
+func (a *AuthHandler) sendVerification(user models.User) {
+	token, err := a.issueUserToken(user, models.TokenVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		log.Printf("Failed to create verification token for %s: %v", user.UserID, err)
+		return
+	}
+
+	err = a.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Atlas email address",
+		Body: "Confirm your email address by opening the link below. It expires in 48 hours.\n\n" +
+			a.link("/verify-email", token) + "\n",
+	})
+	if err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.UserID, err)
+	}
+}
+
+func (a *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req TokenRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	err = a.consumeUserToken(req.Token, models.TokenVerifyEmail, func(tx *gorm.DB, userID string) error {
+		return tx.Model(&models.User{}).Where("user_id = ?", userID).Update("email_verified_at", time.Now()).Error
+	})
+	if errors.Is(err, errTokenInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+func (a *AuthHandler) ResendVerification(c *gin.Context) {
+	var user models.User
+	result := a.DB.Where("user_id = ?", c.GetString("user_id")).First(&user)
+	if result.Error != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	a.sendVerification(user)
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// ForgotPassword answers the same way whether or not the account exists, so
+// it cannot be used to find registered emails.
+func (a *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var user models.User
+	result := a.DB.Where("email = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&user)
+	if result.Error == nil {
+		a.sendPasswordReset(user)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a reset email has been sent"})
+}
+
+func (a *AuthHandler) sendPasswordReset(user models.User) {
+	token, err := a.issueUserToken(user, models.TokenResetPassword, resetPasswordTTL)
+	if err != nil {
+		log.Printf("Failed to create reset token for %s: %v", user.UserID, err)
+		return
+	}
+
+	err = a.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Atlas password",
+		Body: "Someone asked to reset the password for this account. If it was you, open the link below within an hour. " +
+			"Otherwise you can ignore this email.\n\n" + a.link("/reset-password", token) + "\n",
+	})
+	if err != nil {
+		log.Printf("Failed to send reset email to %s: %v", user.UserID, err)
+	}
+}
+
+// ResetPassword sets a new password and signs the user out everywhere. The
+// reset also proves the user owns the email, so it is marked verified.
+func (a *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(strings.TrimSpace(req.Password)), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	now := time.Now()
+	err = a.consumeUserToken(req.Token, models.TokenResetPassword, func(tx *gorm.DB, userID string) error {
+		err := tx.Model(&models.User{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"password":          string(hashedPassword),
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", now),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+	if errors.Is(err, errTokenInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reset link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+func randomToken(prefix string) string {
+	randomBytes := make([]byte, 32)
+	rand.Read(randomBytes)
+	return prefix + hex.EncodeToString(randomBytes)
+}
@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/api"
+	"github.com/k1ngalph0x/atlas/services/identity-service/mailer"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	"gorm.io/gorm"
+)
+
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("expected an email to be sent")
+	}
+	match := tokenPattern.FindStringSubmatch(m.sent[len(m.sent)-1].Body)
+	if match == nil {
+		t.Fatalf("no token in email: %s", m.sent[len(m.sent)-1].Body)
+	}
+	return match[1]
+}
+
+func setupAccountRouter(t *testing.T) (*gorm.DB, *api.AuthHandler, *recordingMailer, *gin.Engine) {
+	t.Helper()
+	db := setupTestDB(t)
+	h, r := setupRouter(db)
+	mail := &recordingMailer{}
+	h.Mailer = mail
+	r.POST("/auth/refresh", h.Refresh)
+	r.POST("/auth/verify-email", h.VerifyEmail)
+	r.POST("/auth/forgot-password", h.ForgotPassword)
+	r.POST("/auth/reset-password", h.ResetPassword)
+	return db, h, mail, r
+}
+
+func TestVerifyEmail(t *testing.T) {
+	db, h, mail, r := setupAccountRouter(t)
+	h.Config.ACCOUNT.RequireVerifiedEmail = true
+
+	credentials := map[string]any{"email": "jane@example.com", "password": "password123"}
+	w := post(t, r, "/auth/signup", credentials)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: expected 201, got %d", w.Code)
+	}
+	signup := decodeBody(t, w)
+	if signup["token"] != nil || signup["refresh_token"] != nil || signup["verification_required"] != true {
+		t.Errorf("expected signup to withhold a session until the email is verified, got %v", signup)
+	}
+	var sessions int64
+	db.Model(&models.Session{}).Count(&sessions)
+	if sessions != 0 {
+		t.Errorf("expected no session for an unverified signup, got %d", sessions)
+	}
+	token := mail.lastToken(t)
+	if mail.sent[0].To != "jane@example.com" {
+		t.Errorf("expected verification email to jane@example.com, got %s", mail.sent[0].To)
+	}
+
+	if w := post(t, r, "/auth/signin", credentials); w.Code != http.StatusForbidden {
+		t.Fatalf("unverified signin: expected 403, got %d", w.Code)
+	}
+
+	if w := post(t, r, "/auth/verify-email", map[string]any{"token": token}); w.Code != http.StatusOK {
+		t.Fatalf("verify: expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+	if w := post(t, r, "/auth/verify-email", map[string]any{"token": token}); w.Code != http.StatusBadRequest {
+		t.Errorf("reused token: expected 400, got %d", w.Code)
+	}
+
+	var user models.User
+	db.Where("email = ?", "jane@example.com").First(&user)
+	if user.EmailVerifiedAt == nil {
+		t.Fatal("expected email_verified_at to be set")
+	}
+
+	if w := post(t, r, "/auth/signin", credentials); w.Code != http.StatusOK {
+		t.Errorf("verified signin: expected 200, got %d", w.Code)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	db, _, mail, r := setupAccountRouter(t)
+
+	w := post(t, r, "/auth/signup", map[string]any{"email": "jane@example.com", "password": "password123"})
+	session := decodeBody(t, w)
+
+	if w := post(t, r, "/auth/forgot-password", map[string]any{"email": "nobody@example.com"}); w.Code != http.StatusAccepted {
+		t.Fatalf("unknown email: expected 202, got %d", w.Code)
+	}
+	if len(mail.sent) != 1 {
+		t.Fatalf("expected no email for an unknown account, got %d emails", len(mail.sent))
+	}
+
+	if w := post(t, r, "/auth/forgot-password", map[string]any{"email": "jane@example.com"}); w.Code != http.StatusAccepted {
+		t.Fatalf("forgot: expected 202, got %d", w.Code)
+	}
+	stale := mail.lastToken(t)
+	post(t, r, "/auth/forgot-password", map[string]any{"email": "jane@example.com"})
+	token := mail.lastToken(t)
+
+	if w := post(t, r, "/auth/reset-password", map[string]any{"token": stale, "password": "newpassword1"}); w.Code != http.StatusBadRequest {
+		t.Errorf("superseded token: expected 400, got %d", w.Code)
+	}
+	if w := post(t, r, "/auth/reset-password", map[string]any{"token": token, "password": "newpassword1"}); w.Code != http.StatusOK {
+		t.Fatalf("reset: expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+	if w := post(t, r, "/auth/reset-password", map[string]any{"token": token, "password": "another-one"}); w.Code != http.StatusBadRequest {
+		t.Errorf("reused token: expected 400, got %d", w.Code)
+	}
+
+	if w := post(t, r, "/auth/refresh", map[string]any{"refresh_token": session["refresh_token"]}); w.Code != http.StatusUnauthorized {
+		t.Errorf("old session: expected 401 after reset, got %d", w.Code)
+	}
+	if w := post(t, r, "/auth/signin", map[string]any{"email": "jane@example.com", "password": "password123"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("old password: expected 401, got %d", w.Code)
+	}
+	if w := post(t, r, "/auth/signin", map[string]any{"email": "jane@example.com", "password": "newpassword1"}); w.Code != http.StatusOK {
+		t.Errorf("new password: expected 200, got %d", w.Code)
+	}
+
+	post(t, r, "/auth/forgot-password", map[string]any{"email": "jane@example.com"})
+	expired := mail.lastToken(t)
+	db.Model(&models.UserToken{}).Where("used_at IS NULL").Update("expires_at", time.Now().Add(-time.Minute))
+	if w := post(t, r, "/auth/reset-password", map[string]any{"token": expired, "password": "newpassword2"}); w.Code != http.StatusBadRequest {
+		t.Errorf("expired token: expected 400, got %d", w.Code)
+	}
+}
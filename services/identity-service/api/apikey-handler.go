@@ -0,0 +1,250 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultKeyName = "Default"
+
+	defaultRotationGrace = 24 * time.Hour
+	maxRotationGrace     = 7 * 24 * time.Hour
+)
+
+// KeyEventPublisher tells ingestion-service which cached keys to drop.
+type KeyEventPublisher interface {
+	PublishKeyEvent(event sharedModels.APIKeyEvent) error
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// RotateAPIKeyRequest sets how long the old key keeps working. Omitted means
+// 24 hours; 0 expires the old key immediately.
+type RotateAPIKeyRequest struct {
+	GracePeriodSeconds *int64 `json:"grace_period_seconds" binding:"omitempty,min=0"`
+}
+
+type APIKeyWithRawKey struct {
+	sharedModels.APIKey
+	RawAPIKey string `json:"api_key"`
+}
+
+// requireProjectRole loads :project_id and checks the caller's role in its
+// organization, writing the error response on failure.
+func (p *ProjectHandler) requireProjectRole(c *gin.Context, min string) (sharedModels.Project, string, bool) {
+	userId := c.GetString("user_id")
+	if userId == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return sharedModels.Project{}, "", false
+	}
+
+	var project sharedModels.Project
+	err := p.DB.Where("id = ?", c.Param("project_id")).First(&project).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project"})
+		return sharedModels.Project{}, "", false
+	}
+
+	membership, merr := p.membership(project.OrganizationID, userId)
+	if err != nil || merr != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return sharedModels.Project{}, "", false
+	}
+
+	if !roleAtLeast(membership.Role, min) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+		return sharedModels.Project{}, "", false
+	}
+
+	return project, userId, true
+}
+
+func (p *ProjectHandler) GetAPIKeys(c *gin.Context) {
+	project, _, ok := p.requireProjectRole(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var keys []sharedModels.APIKey
+	result := p.DB.Where("project_id = ?", project.ID).Order("created_at desc").Find(&keys)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+func (p *ProjectHandler) CreateAPIKey(c *gin.Context) {
+	project, userId, ok := p.requireProjectRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	key, rawKey := sharedModels.NewAPIKey(project.ID, strings.TrimSpace(req.Name), userId)
+	result := p.DB.Create(key)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"key": APIKeyWithRawKey{APIKey: *key, RawAPIKey: rawKey}})
+}
+
+// RotateAPIKey issues a replacement with the same name and lets the old key
+// keep working for the grace period, so clients can be redeployed without
+// dropping events.
+func (p *ProjectHandler) RotateAPIKey(c *gin.Context) {
+	project, userId, ok := p.requireProjectRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		err := c.ShouldBindJSON(&req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	grace := defaultRotationGrace
+	if req.GracePeriodSeconds != nil {
+		grace = time.Duration(*req.GracePeriodSeconds) * time.Second
+	}
+	if grace > maxRotationGrace {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Grace period cannot exceed 7 days"})
+		return
+	}
+
+	old, ok := p.activeKey(c, project.ID)
+	if !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(grace)
+	if old.ExpiresAt != nil && old.ExpiresAt.Before(expiresAt) {
+		expiresAt = *old.ExpiresAt
+	}
+
+	key, rawKey := sharedModels.NewAPIKey(project.ID, old.Name, userId)
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&old).Update("expires_at", expiresAt).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key"})
+		return
+	}
+
+	p.publishKeyEvent(old, sharedModels.APIKeyRotated)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"key":      APIKeyWithRawKey{APIKey: *key, RawAPIKey: rawKey},
+		"previous": old,
+	})
+}
+
+func (p *ProjectHandler) RevokeAPIKey(c *gin.Context) {
+	project, _, ok := p.requireProjectRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	key, ok := p.activeKey(c, project.ID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	result := p.DB.Model(&key).Update("revoked_at", now)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	p.publishKeyEvent(key, sharedModels.APIKeyRevoked)
+
+	c.JSON(http.StatusOK, gin.H{"key": key})
+}
+
+func (p *ProjectHandler) activeKey(c *gin.Context, projectID string) (sharedModels.APIKey, bool) {
+	var key sharedModels.APIKey
+	result := p.DB.Where("id = ? AND project_id = ?", c.Param("key_id"), projectID).First(&key)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return sharedModels.APIKey{}, false
+	}
+
+	if !key.Active(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key is already revoked or expired"})
+		return sharedModels.APIKey{}, false
+	}
+
+	return key, true
+}
+
+// publishKeyEvent is best effort: ingestion-service caches keys for a bounded
+// TTL, so a lost event only delays the change until the entry expires.
+func (p *ProjectHandler) publishKeyEvent(key sharedModels.APIKey, action string) {
+	if p.KeyEvents == nil {
+		return
+	}
+
+	err := p.KeyEvents.PublishKeyEvent(sharedModels.APIKeyEvent{
+		KeyID:      key.ID,
+		ProjectID:  key.ProjectID,
+		KeyHash:    key.KeyHash,
+		Action:     action,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to publish %s event for key %s: %v", action, key.ID, err)
+	}
+}
+
+// BackfillProjectKeys copies the key stored on projects created before the
+// api_keys table into it, so those keys keep authenticating.
+func BackfillProjectKeys(db *gorm.DB) error {
+	var projects []sharedModels.Project
+	err := db.Where("NOT EXISTS (SELECT 1 FROM api_keys WHERE api_keys.project_id = projects.id)").Find(&projects).Error
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		err := db.Create(&sharedModels.APIKey{
+			ProjectID: project.ID,
+			Name:      defaultKeyName,
+			KeyHash:   project.APIKey,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
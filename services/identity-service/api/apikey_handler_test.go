@@ -0,0 +1,76 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+)
+
+type recordingPublisher struct {
+	events []sharedModels.APIKeyEvent
+}
+
+func (p *recordingPublisher) PublishKeyEvent(event sharedModels.APIKeyEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestAPIKeys_RotateAndRevoke(t *testing.T) {
+	db := setupMembershipDB(t)
+	publisher := &recordingPublisher{}
+	r := setupProjectRouter(db, publisher)
+	orgID := createOrg(t, r, "owner@example.com")
+
+	w := do(t, r, http.MethodPost, "/project/create-project", "owner@example.com", map[string]string{"organization_id": orgID, "project_name": "web"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create project: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	project := decodeBody(t, w)["project"].(map[string]any)
+	keys := "/projects/" + project["id"].(string) + "/keys"
+
+	var initial sharedModels.APIKey
+	if err := db.Where("key_hash = ?", sharedModels.HashAPIKey(project["api_key"].(string))).First(&initial).Error; err != nil {
+		t.Fatalf("expected the project's key in api_keys: %v", err)
+	}
+
+	if w := do(t, r, http.MethodGet, keys, "viewer@example.com", nil); w.Code != http.StatusNotFound {
+		t.Errorf("non-member listing keys: expected 404, got %d", w.Code)
+	}
+
+	w = do(t, r, http.MethodPost, keys+"/"+initial.ID+"/rotate", "owner@example.com", map[string]int64{"grace_period_seconds": 3600})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("rotate: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	rotated := decodeBody(t, w)["key"].(map[string]any)
+
+	db.First(&initial, "id = ?", initial.ID)
+	if initial.ExpiresAt == nil || !initial.Active(time.Now()) || initial.Active(time.Now().Add(2*time.Hour)) {
+		t.Errorf("expected old key to stay active for the grace period, expires_at=%v", initial.ExpiresAt)
+	}
+
+	rotatedID := rotated["id"].(string)
+	if w := do(t, r, http.MethodDelete, keys+"/"+rotatedID, "owner@example.com", nil); w.Code != http.StatusOK {
+		t.Fatalf("revoke: expected 200, got %d", w.Code)
+	}
+	if w := do(t, r, http.MethodDelete, keys+"/"+rotatedID, "owner@example.com", nil); w.Code != http.StatusConflict {
+		t.Errorf("second revoke: expected 409, got %d", w.Code)
+	}
+
+	var revoked sharedModels.APIKey
+	db.First(&revoked, "id = ?", rotatedID)
+	if revoked.Active(time.Now()) {
+		t.Error("expected revoked key to be inactive")
+	}
+
+	if len(publisher.events) != 2 {
+		t.Fatalf("expected 2 key events, got %d", len(publisher.events))
+	}
+	if e := publisher.events[0]; e.Action != sharedModels.APIKeyRotated || e.KeyHash != initial.KeyHash {
+		t.Errorf("expected rotated event for the initial key, got %+v", e)
+	}
+	if e := publisher.events[1]; e.Action != sharedModels.APIKeyRevoked || e.KeyHash != revoked.KeyHash {
+		t.Errorf("expected revoked event for the rotated key, got %+v", e)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -9,6 +10,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/k1ngalph0x/atlas/services/identity-service/config"
+	"github.com/k1ngalph0x/atlas/services/identity-service/keys"
+	"github.com/k1ngalph0x/atlas/services/identity-service/mailer"
 	"github.com/k1ngalph0x/atlas/services/identity-service/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -17,6 +20,8 @@ import (
 type AuthHandler struct {
 	DB *gorm.DB
 	Config *config.Config
+	Keys *keys.KeySet
+	Mailer mailer.Mailer
 }
 
 type SignUpRequest struct {
@@ -32,21 +37,34 @@ type SignInRequest struct{
 type Claims struct{
 	UserId string `json:"user_id"`
 	Email string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func NewAuthHandler(db *gorm.DB, config *config.Config) *AuthHandler {
+	keySet, err := keys.Load(config.TOKEN.SigningKeyFile, config.TOKEN.PreviousKeyFiles)
+	if err != nil{
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+
+	if config.TOKEN.SigningKeyFile == ""{
+		log.Println("JWT_SIGNING_KEY_FILE not set, signing with a temporary key")
+	}
+
 	return &AuthHandler{
 		DB: db,
 		Config: config,
+		Keys: keySet,
+		Mailer: mailer.New(config),
 	}
 }
 
-func(a *AuthHandler) GenerateJWT(userId, email string)(string, error){
-	expiration := time.Now().Add(24 * time.Hour)
+func(a *AuthHandler) generateAccessToken(userId, email, sessionID string)(string, error){
+	expiration := time.Now().Add(a.accessTokenTTL())
 	claims := &Claims{
 		UserId: userId,
 		Email: email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiration),
 			IssuedAt: jwt.NewNumericDate(time.Now()),
@@ -55,8 +73,7 @@ func(a *AuthHandler) GenerateJWT(userId, email string)(string, error){
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(a.Config.TOKEN.JwtKey))
+	tokenString, err := a.Keys.Sign(claims)
 	if err != nil{
 		return "", err
 	}
@@ -108,7 +125,22 @@ func(a *AuthHandler) SignUp(c *gin.Context){
 		return 
 	}
 
-	token, err := a.GenerateJWT(user.UserID, user.Email)
+	a.sendVerification(user)
+
+	if a.Config.ACCOUNT.RequireVerifiedEmail{
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "User created, verify your email address to sign in",
+			"verification_required": true,
+			"user": gin.H{
+				"id":    user.UserID,
+				"email": user.Email,
+				"email_verified": false,
+			},
+		})
+		return
+	}
+
+	pair, err := a.startSession(user)
 	if err != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error":"Something went wrong"})
 		return 
@@ -116,10 +148,13 @@ func(a *AuthHandler) SignUp(c *gin.Context){
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User created successfully",
-		"token":   token,
+		"token":   pair.Token,
+		"refresh_token": pair.RefreshToken,
+		"expires_in": pair.ExpiresIn,
 		"user": gin.H{
 			"id":    user.UserID,
 			"email": user.Email,
+			"email_verified": false,
 		},
 	})
 }
@@ -156,7 +191,21 @@ func(a *AuthHandler) SignIn(c *gin.Context){
 		return
 	}
 
-	token, err := a.GenerateJWT(existingUser.UserID, existingUser.Email)
+	if a.Config.ACCOUNT.RequireVerifiedEmail && existingUser.EmailVerifiedAt == nil{
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email address not verified"})
+		return
+	}
+
+	if existingUser.TOTPEnabledAt != nil{
+		a.startTwoFactorChallenge(c, existingUser)
+		return
+	}
+
+	a.completeSignIn(c, existingUser)
+}
+
+func(a *AuthHandler) completeSignIn(c *gin.Context, user models.User){
+	pair, err := a.startSession(user)
 	if err != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
@@ -164,7 +213,13 @@ func(a *AuthHandler) SignIn(c *gin.Context){
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":"Login successful", 
-		"token":token, 
-		"email": existingUser.Email,
+		"token":pair.Token, 
+		"refresh_token": pair.RefreshToken,
+		"expires_in": pair.ExpiresIn,
+		"email": user.Email,
 	})	
-}
\ No newline at end of file
+}
+func(a *AuthHandler) JWKS(c *gin.Context){
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, a.Keys.JWKS())
+}
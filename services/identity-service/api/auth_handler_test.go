@@ -11,6 +11,7 @@ import (
 	"github.com/k1ngalph0x/atlas/services/identity-service/api"
 	"github.com/k1ngalph0x/atlas/services/identity-service/config"
 	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -25,7 +26,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open test db: %v", err)
 	}
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Session{}, &models.RefreshToken{}, &models.UserToken{}, &models.RecoveryCode{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	return db
@@ -33,9 +34,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 
 func setupRouter(db *gorm.DB) (*api.AuthHandler, *gin.Engine) {
 	gin.SetMode(gin.TestMode)
-	cfg := &config.Config{
-		TOKEN: config.TokenConfig{JwtKey: "test-secret-key"},
-	}
+	cfg := &config.Config{}
 	h := api.NewAuthHandler(db, cfg)
 	r := gin.New()
 	r.POST("/auth/signup", h.SignUp)
@@ -204,16 +203,49 @@ func TestSignIn_MissingFields(t *testing.T) {
 	}
 }
 
+// signUpToken signs up a user through h and returns the access token of the
+// session that creates.
+func signUpToken(t *testing.T, h *api.AuthHandler) string {
+	t.Helper()
+	r := gin.New()
+	r.POST("/auth/signup", h.SignUp)
 
-func TestGenerateJWT_ValidToken(t *testing.T) {
-	cfg := &config.Config{TOKEN: config.TokenConfig{JwtKey: "test-secret"}}
-	h := api.NewAuthHandler(nil, cfg)
-
-	token, err := h.GenerateJWT("user-123", "test@example.com")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	w := post(t, r, "/auth/signup", map[string]any{"email": "user@example.com", "password": "password123"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d — body: %s", w.Code, w.Body.String())
 	}
+
+	token, _ := decodeBody(t, w)["token"].(string)
 	if token == "" {
-		t.Error("expected non-empty token")
+		t.Fatal("expected a token in the sign-up response")
 	}
-}
\ No newline at end of file
+	return token
+}
+
+func TestJWKS_VerifiesSessionToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	h := api.NewAuthHandler(setupTestDB(t), cfg)
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", h.JWKS)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	token := signUpToken(t, h)
+
+	claims := &api.Claims{}
+	verifier := jwks.NewVerifier(srv.URL + "/.well-known/jwks.json")
+	if _, err := verifier.Parse(token, claims); err != nil {
+		t.Fatalf("expected token to verify against JWKS, got %v", err)
+	}
+	if claims.UserId == "" || claims.Email != "user@example.com" || claims.SessionID == "" {
+		t.Errorf("expected user and session claims, got %+v", claims)
+	}
+
+	other := api.NewAuthHandler(setupTestDB(t), cfg)
+	foreign := signUpToken(t, other)
+	if _, err := verifier.Parse(foreign, &api.Claims{}); err == nil {
+		t.Error("expected token signed by an unpublished key to be rejected")
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/gorm"
+)
+
+// UpdateLimitsRequest replaces a project's limit overrides. Omitted fields go
+// back to ingestion-service's defaults.
+type UpdateLimitsRequest struct {
+	EventsPerSecond    *float64 `json:"events_per_second" binding:"omitempty,min=0"`
+	Burst              *int     `json:"burst" binding:"omitempty,min=1"`
+	KeyEventsPerSecond *float64 `json:"key_events_per_second" binding:"omitempty,min=0"`
+	KeyBurst           *int     `json:"key_burst" binding:"omitempty,min=1"`
+	MonthlyQuota       *int64   `json:"monthly_quota" binding:"omitempty,min=0"`
+	SpikeProtection    *bool    `json:"spike_protection"`
+}
+
+func (p *ProjectHandler) GetLimits(c *gin.Context) {
+	project, _, ok := p.requireProjectRole(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	limits := sharedModels.ProjectLimit{ProjectID: project.ID}
+	err := p.DB.Where("project_id = ?", project.ID).First(&limits).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch limits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"limits": limits})
+}
+
+func (p *ProjectHandler) UpdateLimits(c *gin.Context) {
+	project, _, ok := p.requireProjectRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req UpdateLimitsRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	limits := sharedModels.ProjectLimit{
+		ProjectID:          project.ID,
+		EventsPerSecond:    req.EventsPerSecond,
+		Burst:              req.Burst,
+		KeyEventsPerSecond: req.KeyEventsPerSecond,
+		KeyBurst:           req.KeyBurst,
+		MonthlyQuota:       req.MonthlyQuota,
+		SpikeProtection:    req.SpikeProtection,
+	}
+
+	result := p.DB.Save(&limits)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update limits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"limits": limits})
+}
@@ -0,0 +1,453 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/gorm"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+var roleRank = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleMember: 2,
+	models.RoleAdmin:  3,
+	models.RoleOwner:  4,
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"  binding:"required,oneof=owner admin member viewer"`
+}
+
+type MemberResponse struct {
+	models.Membership
+	Email string `json:"email"`
+}
+
+type InvitationResponse struct {
+	models.Invitation
+	OrganizationName string `json:"organization_name"`
+}
+
+func roleAtLeast(role string, min string) bool {
+	return roleRank[role] >= roleRank[min]
+}
+
+func (p *ProjectHandler) membership(orgID string, userID string) (models.Membership, error) {
+	var membership models.Membership
+	err := p.DB.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error
+	return membership, err
+}
+
+// requireRole loads the caller's membership of :org_id and writes the error
+// response when it is missing or below min.
+func (p *ProjectHandler) requireRole(c *gin.Context, min string) (models.Membership, bool) {
+	userId := c.GetString("user_id")
+	if userId == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return models.Membership{}, false
+	}
+
+	membership, err := p.membership(c.Param("org_id"), userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return models.Membership{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch membership"})
+		return models.Membership{}, false
+	}
+
+	if !roleAtLeast(membership.Role, min) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+		return models.Membership{}, false
+	}
+
+	return membership, true
+}
+
+func (p *ProjectHandler) isLastOwner(tx *gorm.DB, member models.Membership) (bool, error) {
+	if member.Role != models.RoleOwner {
+		return false, nil
+	}
+
+	var owners int64
+	err := tx.Model(&models.Membership{}).
+		Where("organization_id = ? AND role = ?", member.OrganizationID, models.RoleOwner).
+		Count(&owners).Error
+	return owners <= 1, err
+}
+
+func (p *ProjectHandler) GetMembers(c *gin.Context) {
+	_, ok := p.requireRole(c, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var members []MemberResponse
+	result := p.DB.Table("memberships").
+		Select("memberships.*, users.email").
+		Joins("JOIN users ON users.user_id = memberships.user_id").
+		Where("memberships.organization_id = ?", c.Param("org_id")).
+		Order("memberships.created_at asc").
+		Scan(&members)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func (p *ProjectHandler) UpdateMemberRole(c *gin.Context) {
+	caller, ok := p.requireRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	target, err := p.membership(c.Param("org_id"), c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if (target.Role == models.RoleOwner || req.Role == models.RoleOwner) && caller.Role != models.RoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can grant or revoke the owner role"})
+		return
+	}
+
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		if req.Role != models.RoleOwner {
+			last, err := p.isLastOwner(tx, target)
+			if err != nil {
+				return err
+			}
+			if last {
+				return errLastOwner
+			}
+		}
+
+		return tx.Model(&target).Update("role", req.Role).Error
+	})
+	if errors.Is(err, errLastOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Organization must keep at least one owner"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"member": target})
+}
+
+// RemoveMember lets admins remove members and anyone leave, but never removes
+// the last owner.
+func (p *ProjectHandler) RemoveMember(c *gin.Context) {
+	userId := c.GetString("user_id")
+	targetID := c.Param("user_id")
+
+	min := models.RoleAdmin
+	if targetID == userId {
+		min = models.RoleViewer
+	}
+
+	caller, ok := p.requireRole(c, min)
+	if !ok {
+		return
+	}
+
+	target, err := p.membership(c.Param("org_id"), targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if target.Role == models.RoleOwner && caller.Role != models.RoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can remove an owner"})
+		return
+	}
+
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		last, err := p.isLastOwner(tx, target)
+		if err != nil {
+			return err
+		}
+		if last {
+			return errLastOwner
+		}
+
+		return tx.Delete(&target).Error
+	})
+	if errors.Is(err, errLastOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Organization must keep at least one owner"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "removed"})
+}
+
+func (p *ProjectHandler) CreateInvitation(c *gin.Context) {
+	caller, ok := p.requireRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req CreateInvitationRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.Role == models.RoleOwner && caller.Role != models.RoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can invite owners"})
+		return
+	}
+
+	orgID := c.Param("org_id")
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	var existing int64
+	p.DB.Table("memberships").
+		Joins("JOIN users ON users.user_id = memberships.user_id").
+		Where("memberships.organization_id = ? AND users.email = ?", orgID, email).
+		Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		return
+	}
+
+	token := generateInvitationToken()
+	invitation := models.Invitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           req.Role,
+		TokenHash:      sharedModels.HashAPIKey(token),
+		InvitedBy:      caller.UserID,
+		Status:         models.InvitationPending,
+		ExpiresAt:      time.Now().Add(invitationTTL),
+	}
+
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Invitation{}).
+			Where("organization_id = ? AND email = ? AND status = ?", orgID, email, models.InvitationPending).
+			Update("status", models.InvitationRevoked).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&invitation).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	log.Printf("Invitation %s created for %s", invitation.ID, email)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"invitation": invitation,
+		"token":      token,
+	})
+}
+
+func (p *ProjectHandler) GetInvitations(c *gin.Context) {
+	_, ok := p.requireRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	var invitations []models.Invitation
+	result := p.DB.Where("organization_id = ? AND status = ? AND expires_at > ?", c.Param("org_id"), models.InvitationPending, time.Now()).
+		Order("created_at desc").Find(&invitations)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+func (p *ProjectHandler) RevokeInvitation(c *gin.Context) {
+	_, ok := p.requireRole(c, models.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	result := p.DB.Model(&models.Invitation{}).
+		Where("id = ? AND organization_id = ? AND status = ?", c.Param("invitation_id"), c.Param("org_id"), models.InvitationPending).
+		Update("status", models.InvitationRevoked)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
+
+func (p *ProjectHandler) GetMyInvitations(c *gin.Context) {
+	email := strings.ToLower(c.GetString("email"))
+
+	var invitations []InvitationResponse
+	result := p.DB.Table("invitations").
+		Select("invitations.*, organizations.organization_name").
+		Joins("JOIN organizations ON organizations.id = invitations.organization_id").
+		Where("invitations.email = ? AND invitations.status = ? AND invitations.expires_at > ?", email, models.InvitationPending, time.Now()).
+		Order("invitations.created_at desc").
+		Scan(&invitations)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+func (p *ProjectHandler) AcceptInvitation(c *gin.Context) {
+	invitation, ok := p.pendingInvitation(c)
+	if !ok {
+		return
+	}
+
+	userId := c.GetString("user_id")
+	now := time.Now()
+
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		err := tx.Model(&models.Membership{}).
+			Where("organization_id = ? AND user_id = ?", invitation.OrganizationID, userId).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		if existing == 0 {
+			err = tx.Create(&models.Membership{
+				OrganizationID: invitation.OrganizationID,
+				UserID:         userId,
+				Role:           invitation.Role,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&invitation).Updates(map[string]interface{}{
+			"status":       models.InvitationAccepted,
+			"responded_at": now,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":          models.InvitationAccepted,
+		"organization_id": invitation.OrganizationID,
+		"role":            invitation.Role,
+	})
+}
+
+func (p *ProjectHandler) DeclineInvitation(c *gin.Context) {
+	invitation, ok := p.pendingInvitation(c)
+	if !ok {
+		return
+	}
+
+	result := p.DB.Model(&invitation).Updates(map[string]interface{}{
+		"status":       models.InvitationDeclined,
+		"responded_at": time.Now(),
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": models.InvitationDeclined})
+}
+
+// pendingInvitation looks up the invitation for :token and checks it is still
+// open and addressed to the caller's email.
+func (p *ProjectHandler) pendingInvitation(c *gin.Context) (models.Invitation, bool) {
+	var invitation models.Invitation
+	result := p.DB.Where("token_hash = ?", sharedModels.HashAPIKey(c.Param("token"))).First(&invitation)
+	if result.Error != nil || invitation.Status != models.InvitationPending {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return models.Invitation{}, false
+	}
+
+	if time.Now().After(invitation.ExpiresAt) {
+		c.JSON(http.StatusGone, gin.H{"error": "Invitation has expired"})
+		return models.Invitation{}, false
+	}
+
+	if !strings.EqualFold(invitation.Email, c.GetString("email")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invitation was sent to a different email"})
+		return models.Invitation{}, false
+	}
+
+	return invitation, true
+}
+
+// BackfillOwnerMemberships gives every organization created before
+// memberships existed an owner membership for its creator.
+func BackfillOwnerMemberships(db *gorm.DB) error {
+	var orgs []models.Organization
+	err := db.Where("NOT EXISTS (SELECT 1 FROM memberships WHERE memberships.organization_id = organizations.id)").Find(&orgs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		err := db.Create(&models.Membership{
+			OrganizationID: org.ID,
+			UserID:         org.UserID,
+			Role:           models.RoleOwner,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var errLastOwner = errors.New("organization must keep at least one owner")
+
+func generateInvitationToken() string {
+	randomBytes := make([]byte, 32)
+	rand.Read(randomBytes)
+	return "inv_" + hex.EncodeToString(randomBytes)
+}
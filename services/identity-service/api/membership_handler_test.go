@@ -0,0 +1,163 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/api"
+	"github.com/k1ngalph0x/atlas/services/identity-service/config"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupMembershipDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "identity.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	err = db.AutoMigrate(&models.User{}, &models.Organization{}, &models.Membership{}, &models.Invitation{}, &sharedModels.Project{}, &sharedModels.APIKey{})
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	for _, email := range []string{"owner@example.com", "admin@example.com", "viewer@example.com", "new@example.com"} {
+		db.Create(&models.User{UserID: email, Email: email, Password: "x"})
+	}
+	return db
+}
+
+// setupMembershipRouter authenticates requests from the X-User header so tests
+// can act as any user without minting tokens.
+func setupMembershipRouter(db *gorm.DB) *gin.Engine {
+	return setupProjectRouter(db, nil)
+}
+
+func setupProjectRouter(db *gorm.DB, keyEvents api.KeyEventPublisher) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := api.NewProjectHandler(db, &config.Config{}, keyEvents)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+		c.Set("email", c.GetHeader("X-User"))
+		c.Next()
+	})
+	r.POST("/project/create-organization", h.CreateOrganization)
+	r.PUT("/organizations/:org_id/members/:user_id", h.UpdateMemberRole)
+	r.DELETE("/organizations/:org_id/members/:user_id", h.RemoveMember)
+	r.POST("/organizations/:org_id/invitations", h.CreateInvitation)
+	r.POST("/invitations/:token/accept", h.AcceptInvitation)
+	r.POST("/project/create-project", h.CreateProject)
+	r.GET("/projects/:project_id/keys", h.GetAPIKeys)
+	r.POST("/projects/:project_id/keys", h.CreateAPIKey)
+	r.POST("/projects/:project_id/keys/:key_id/rotate", h.RotateAPIKey)
+	r.DELETE("/projects/:project_id/keys/:key_id", h.RevokeAPIKey)
+	return r
+}
+
+func do(t *testing.T, r *gin.Engine, method string, path string, user string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func createOrg(t *testing.T, r *gin.Engine, owner string) string {
+	t.Helper()
+	w := do(t, r, http.MethodPost, "/project/create-organization", owner, map[string]string{"organization_name": "acme"})
+	if w.Code != http.StatusCreated && w.Code != http.StatusOK {
+		t.Fatalf("create organization: expected success, got %d: %s", w.Code, w.Body.String())
+	}
+	org := decodeBody(t, w)["organization"].(map[string]any)
+	return org["id"].(string)
+}
+
+func invite(t *testing.T, r *gin.Engine, orgID string, from string, email string, role string) string {
+	t.Helper()
+	w := do(t, r, http.MethodPost, "/organizations/"+orgID+"/invitations", from, map[string]string{"email": email, "role": role})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("invite %s: expected 201, got %d: %s", email, w.Code, w.Body.String())
+	}
+	return decodeBody(t, w)["token"].(string)
+}
+
+func TestInvitation_AcceptCreatesMembership(t *testing.T) {
+	db := setupMembershipDB(t)
+	r := setupMembershipRouter(db)
+	orgID := createOrg(t, r, "owner@example.com")
+
+	token := invite(t, r, orgID, "owner@example.com", "new@example.com", models.RoleMember)
+
+	if w := do(t, r, http.MethodPost, "/invitations/"+token+"/accept", "admin@example.com", nil); w.Code != http.StatusForbidden {
+		t.Errorf("accept with other email: expected 403, got %d", w.Code)
+	}
+
+	if w := do(t, r, http.MethodPost, "/invitations/"+token+"/accept", "new@example.com", nil); w.Code != http.StatusOK {
+		t.Fatalf("accept: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var membership models.Membership
+	err := db.Where("organization_id = ? AND user_id = ?", orgID, "new@example.com").First(&membership).Error
+	if err != nil {
+		t.Fatalf("expected membership after accepting: %v", err)
+	}
+	if membership.Role != models.RoleMember {
+		t.Errorf("expected role %q, got %q", models.RoleMember, membership.Role)
+	}
+
+	if w := do(t, r, http.MethodPost, "/invitations/"+token+"/accept", "new@example.com", nil); w.Code != http.StatusNotFound {
+		t.Errorf("second accept: expected 404, got %d", w.Code)
+	}
+}
+
+func TestMembership_RoleChecks(t *testing.T) {
+	db := setupMembershipDB(t)
+	r := setupMembershipRouter(db)
+	orgID := createOrg(t, r, "owner@example.com")
+	db.Create(&models.Membership{OrganizationID: orgID, UserID: "admin@example.com", Role: models.RoleAdmin})
+	db.Create(&models.Membership{OrganizationID: orgID, UserID: "viewer@example.com", Role: models.RoleViewer})
+
+	members := "/organizations/" + orgID + "/members/"
+
+	if w := do(t, r, http.MethodPost, "/organizations/"+orgID+"/invitations", "viewer@example.com", map[string]string{"email": "new@example.com", "role": "member"}); w.Code != http.StatusForbidden {
+		t.Errorf("viewer invite: expected 403, got %d", w.Code)
+	}
+	if w := do(t, r, http.MethodPost, "/organizations/"+orgID+"/invitations", "admin@example.com", map[string]string{"email": "new@example.com", "role": "owner"}); w.Code != http.StatusForbidden {
+		t.Errorf("admin inviting owner: expected 403, got %d", w.Code)
+	}
+	if w := do(t, r, http.MethodPost, "/organizations/"+orgID+"/invitations", "new@example.com", map[string]string{"email": "new@example.com", "role": "member"}); w.Code != http.StatusNotFound {
+		t.Errorf("non-member invite: expected 404, got %d", w.Code)
+	}
+
+	if w := do(t, r, http.MethodPut, members+"owner@example.com", "admin@example.com", map[string]string{"role": "member"}); w.Code != http.StatusForbidden {
+		t.Errorf("admin demoting owner: expected 403, got %d", w.Code)
+	}
+	if w := do(t, r, http.MethodPut, members+"owner@example.com", "owner@example.com", map[string]string{"role": "admin"}); w.Code != http.StatusConflict {
+		t.Errorf("demoting last owner: expected 409, got %d", w.Code)
+	}
+	if w := do(t, r, http.MethodDelete, members+"owner@example.com", "owner@example.com", nil); w.Code != http.StatusConflict {
+		t.Errorf("last owner leaving: expected 409, got %d", w.Code)
+	}
+
+	if w := do(t, r, http.MethodPut, members+"viewer@example.com", "admin@example.com", map[string]string{"role": "member"}); w.Code != http.StatusOK {
+		t.Errorf("admin promoting viewer: expected 200, got %d", w.Code)
+	}
+	if w := do(t, r, http.MethodDelete, members+"viewer@example.com", "viewer@example.com", nil); w.Code != http.StatusOK {
+		t.Errorf("member leaving: expected 200, got %d", w.Code)
+	}
+}
@@ -11,23 +11,30 @@ import (
 )
 
 type ProjectHandler struct {
-	DB     *gorm.DB
-	Config *config.Config
+	DB        *gorm.DB
+	Config    *config.Config
+	KeyEvents KeyEventPublisher
 }
 
 type CreateOrgRequest struct{
 	OrganizationName string `json:"organization_name" binding:"required"`
 }
 
+type OrganizationWithRole struct{
+	models.Organization
+	Role string `json:"role"`
+}
+
 type CreateProjectRequest struct{
 	OrganizationID string `json:"organization_id" binding:"required,uuid"`
 	ProjectName string `json:"project_name" binding:"required"`
 }
 
-func NewProjectHandler(db *gorm.DB, config *config.Config) *ProjectHandler {
+func NewProjectHandler(db *gorm.DB, config *config.Config, keyEvents KeyEventPublisher) *ProjectHandler {
 	return &ProjectHandler{
-		DB:     db,
-		Config: config,
+		DB:        db,
+		Config:    config,
+		KeyEvents: keyEvents,
 	}
 }
 
@@ -52,9 +59,20 @@ func(p *ProjectHandler) CreateOrganization(c *gin.Context){
 		UserID: userId,
 	}
 
-	result := p.DB.Create(&org)
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&org).Error
+		if err != nil{
+			return err
+		}
+
+		return tx.Create(&models.Membership{
+			OrganizationID: org.ID,
+			UserID: userId,
+			Role: models.RoleOwner,
+		}).Error
+	})
 
-	if result.Error != nil{
+	if err != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
 		return
 	}
@@ -77,9 +95,8 @@ func (p *ProjectHandler) CreateProject(c *gin.Context){
         return
 	}
 
-	var org models.Organization
-	result := p.DB.Where("id = ? AND user_id = ?", req.OrganizationID, userId).First(&org)
-	if result.Error != nil{
+	membership, err := p.membership(req.OrganizationID, userId)
+	if err != nil || !roleAtLeast(membership.Role, models.RoleAdmin){
 		c.JSON(http.StatusForbidden, gin.H{"error": "Organization not found or access denied"})
 		return
 	}
@@ -91,8 +108,21 @@ func (p *ProjectHandler) CreateProject(c *gin.Context){
 
 	project, rawKey := sharedModels.NewProject(req.ProjectName, req.OrganizationID)
 
-	result = p.DB.Create(&project)
-	if result.Error != nil{
+	err = p.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&project).Error
+		if err != nil{
+			return err
+		}
+
+		return tx.Create(&sharedModels.APIKey{
+			ProjectID: project.ID,
+			Name: defaultKeyName,
+			Prefix: sharedModels.APIKeyPrefix(rawKey),
+			KeyHash: project.APIKey,
+			CreatedBy: userId,
+		}).Error
+	})
+	if err != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
 		return
 	}
@@ -117,8 +147,13 @@ func(p *ProjectHandler) GetOrganizations(c *gin.Context){
 		return
 	}	
 
-	var organizations []models.Organization
-	result := p.DB.Where("user_id = ?", userId).Find(&organizations)
+	var organizations []OrganizationWithRole
+	result := p.DB.Table("organizations").
+		Select("organizations.*, memberships.role").
+		Joins("JOIN memberships ON memberships.organization_id = organizations.id").
+		Where("memberships.user_id = ?", userId).
+		Order("organizations.created_at asc").
+		Scan(&organizations)
 	if result.Error != nil{
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
 		return
@@ -134,20 +169,10 @@ func  (p *ProjectHandler) GetProjects(c *gin.Context) {
 		return
 	}
 
-	var organizations []models.Organization
-	result := p.DB.Where("user_id = ?", userId).Find(&organizations)
-	if result.Error != nil{
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
-		return
-	}
-
-	orgs := make([]string, len(organizations))
-	for i, org := range organizations{
-		orgs[i] = org.ID
-	}
+	orgs := p.DB.Model(&models.Membership{}).Select("organization_id").Where("user_id = ?", userId)
 
 	var projects []sharedModels.Project
-	result = p.DB.Where("organization_id IN ?", orgs).Find(&projects)
+	result := p.DB.Where("organization_id IN (?)", orgs).Find(&projects)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
 		return
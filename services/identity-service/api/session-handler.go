@@ -0,0 +1,222 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var errRefreshTokenReused = errors.New("refresh token reused")
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPair is returned by sign-in and refresh. Token is the access token.
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func (a *AuthHandler) accessTokenTTL() time.Duration {
+	if a.Config.TOKEN.AccessTokenTTL > 0 {
+		return a.Config.TOKEN.AccessTokenTTL
+	}
+	return defaultAccessTokenTTL
+}
+
+func (a *AuthHandler) refreshTokenTTL() time.Duration {
+	if a.Config.TOKEN.RefreshTokenTTL > 0 {
+		return a.Config.TOKEN.RefreshTokenTTL
+	}
+	return defaultRefreshTokenTTL
+}
+
+// startSession creates a session for user and issues its first token pair.
+func (a *AuthHandler) startSession(user models.User) (TokenPair, error) {
+	now := time.Now()
+	session := models.Session{UserID: user.UserID, LastUsedAt: now}
+
+	var refreshToken string
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&session).Error
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err = a.createRefreshToken(tx, session.ID, now)
+		return err
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return a.tokenPair(user, session.ID, refreshToken)
+}
+
+func (a *AuthHandler) tokenPair(user models.User, sessionID string, refreshToken string) (TokenPair, error) {
+	token, err := a.generateAccessToken(user.UserID, user.Email, sessionID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(a.accessTokenTTL().Seconds()),
+	}, nil
+}
+
+func (a *AuthHandler) createRefreshToken(tx *gorm.DB, sessionID string, now time.Time) (string, error) {
+	raw := generateRefreshToken()
+	err := tx.Create(&models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: sharedModels.HashAPIKey(raw),
+		ExpiresAt: now.Add(a.refreshTokenTTL()),
+	}).Error
+
+	return raw, err
+}
+
+// Refresh exchanges a refresh token for a new pair. Each refresh token works
+// once; presenting one that was already used means it was copied, so the whole
+// session is revoked.
+func (a *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var token models.RefreshToken
+	result := a.DB.Where("token_hash = ?", sharedModels.HashAPIKey(req.RefreshToken)).First(&token)
+	if result.Error != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	var session models.Session
+	result = a.DB.Where("id = ?", token.SessionID).First(&session)
+	if result.Error != nil || session.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil {
+		a.revokeReusedSession(c, session, now)
+		return
+	}
+
+	if now.After(token.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has expired"})
+		return
+	}
+
+	var user models.User
+	result = a.DB.Where("user_id = ?", session.UserID).First(&user)
+	if result.Error != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	var refreshToken string
+	err = a.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+
+		err := tx.Model(&session).Update("last_used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err = a.createRefreshToken(tx, session.ID, now)
+		return err
+	})
+	if errors.Is(err, errRefreshTokenReused) {
+		a.revokeReusedSession(c, session, now)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+
+	pair, err := a.tokenPair(user, session.ID, refreshToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+func (a *AuthHandler) revokeReusedSession(c *gin.Context, session models.Session, now time.Time) {
+	err := a.revokeSession(session.ID, now)
+	if err != nil {
+		log.Printf("Failed to revoke session %s after refresh token reuse: %v", session.ID, err)
+	}
+
+	log.Printf("Refresh token reuse detected for user %s, revoked session %s", session.UserID, session.ID)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, session revoked"})
+}
+
+// Logout revokes the session of the given refresh token, which also rejects
+// the access tokens issued for it.
+func (a *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	var token models.RefreshToken
+	result := a.DB.Where("token_hash = ?", sharedModels.HashAPIKey(req.RefreshToken)).First(&token)
+	if result.Error != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	err = a.revokeSession(token.SessionID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+func (a *AuthHandler) revokeSession(sessionID string, now time.Time) error {
+	return a.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", now).Error
+}
+
+func generateRefreshToken() string {
+	randomBytes := make([]byte, 32)
+	rand.Read(randomBytes)
+	return "rt_" + hex.EncodeToString(randomBytes)
+}
@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/middleware"
+	"gorm.io/gorm"
+)
+
+func setupSessionRouter(t *testing.T) (*gorm.DB, *gin.Engine) {
+	t.Helper()
+	db := setupTestDB(t)
+	h, r := setupRouter(db)
+	r.POST("/auth/refresh", h.Refresh)
+	r.POST("/auth/logout", h.Logout)
+	r.GET("/me", middleware.NewAuthMiddleware(h.Keys, db).RequireAuth(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id")})
+	})
+	return db, r
+}
+
+func signIn(t *testing.T, r *gin.Engine) map[string]any {
+	t.Helper()
+	post(t, r, "/auth/signup", map[string]any{"email": "jane@example.com", "password": "password123"})
+	w := post(t, r, "/auth/signin", map[string]any{"email": "jane@example.com", "password": "password123"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("signin: expected 200, got %d", w.Code)
+	}
+	return decodeBody(t, w)
+}
+
+func getMe(r *gin.Engine, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	_, r := setupSessionRouter(t)
+	session := signIn(t, r)
+
+	if session["refresh_token"] == nil || session["expires_in"] != float64(900) {
+		t.Fatalf("expected refresh token and 15 minute access token, got %v", session)
+	}
+
+	w := post(t, r, "/auth/refresh", map[string]any{"refresh_token": session["refresh_token"]})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+
+	refreshed := decodeBody(t, w)
+	if refreshed["refresh_token"] == session["refresh_token"] {
+		t.Error("expected a new refresh token")
+	}
+	if code := getMe(r, refreshed["token"].(string)); code != http.StatusOK {
+		t.Errorf("refreshed access token: expected 200, got %d", code)
+	}
+}
+
+func TestRefresh_ReuseRevokesSession(t *testing.T) {
+	_, r := setupSessionRouter(t)
+	session := signIn(t, r)
+
+	w := post(t, r, "/auth/refresh", map[string]any{"refresh_token": session["refresh_token"]})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d", w.Code)
+	}
+	refreshed := decodeBody(t, w)
+
+	if w := post(t, r, "/auth/refresh", map[string]any{"refresh_token": session["refresh_token"]}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token: expected 401, got %d", w.Code)
+	}
+
+	if w := post(t, r, "/auth/refresh", map[string]any{"refresh_token": refreshed["refresh_token"]}); w.Code != http.StatusUnauthorized {
+		t.Errorf("latest refresh token after reuse: expected 401, got %d", w.Code)
+	}
+	if code := getMe(r, refreshed["token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("access token after reuse: expected 401, got %d", code)
+	}
+}
+
+func TestLogout_RevokesAccessToken(t *testing.T) {
+	_, r := setupSessionRouter(t)
+	session := signIn(t, r)
+	token := session["token"].(string)
+
+	if code := getMe(r, token); code != http.StatusOK {
+		t.Fatalf("before logout: expected 200, got %d", code)
+	}
+
+	if w := post(t, r, "/auth/logout", map[string]any{"refresh_token": session["refresh_token"]}); w.Code != http.StatusOK {
+		t.Fatalf("logout: expected 200, got %d", w.Code)
+	}
+
+	if code := getMe(r, token); code != http.StatusUnauthorized {
+		t.Errorf("after logout: expected 401, got %d", code)
+	}
+	if w := post(t, r, "/auth/refresh", map[string]any{"refresh_token": session["refresh_token"]}); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/models"
+	"github.com/k1ngalph0x/atlas/services/identity-service/totp"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer            = "Atlas"
+	twoFactorChallengeTTL = 5 * time.Minute
+	maxTwoFactorAttempts  = 5
+	recoveryCodeCount     = 10
+)
+
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	TwoFactorCodeRequest
+}
+
+func (a *AuthHandler) currentUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	result := a.DB.Where("user_id = ?", c.GetString("user_id")).First(&user)
+	if result.Error != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return user, false
+	}
+	return user, true
+}
+
+// SetupTwoFactor stores a new pending secret. It only takes effect once
+// EnableTwoFactor has seen a valid code for it.
+func (a *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	user, ok := a.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	err = a.DB.Model(&user).Update("totp_secret", secret).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": totp.URI(totpIssuer, user.Email, secret),
+	})
+}
+
+// EnableTwoFactor confirms the pending secret and returns the recovery codes.
+// They are only shown this once.
+func (a *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authentication code is required"})
+		return
+	}
+
+	user, ok := a.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor setup first"})
+		return
+	}
+
+	step, valid := totp.Validate(user.TOTPSecret, req.Code, time.Now())
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	var codes []string
+	err = a.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled_at": time.Now(),
+			"totp_last_step":  step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, user.UserID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (a *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, ok := a.requireSecondFactor(c, req)
+	if !ok {
+		return
+	}
+
+	err = a.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", user.UserID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not.
+func (a *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, ok := a.requireSecondFactor(c, req)
+	if !ok {
+		return
+	}
+
+	var codes []string
+	err = a.DB.Transaction(func(tx *gorm.DB) error {
+		codes, err = replaceRecoveryCodes(tx, user.UserID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// requireSecondFactor loads the signed-in user and checks the code in req,
+// for changes to an account that already has 2FA enabled.
+func (a *AuthHandler) requireSecondFactor(c *gin.Context, req TwoFactorCodeRequest) (models.User, bool) {
+	user, ok := a.currentUser(c)
+	if !ok {
+		return user, false
+	}
+
+	if user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return user, false
+	}
+
+	valid, err := a.checkSecondFactor(user, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return user, false
+	}
+	if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return user, false
+	}
+
+	return user, true
+}
+
+// startTwoFactorChallenge is the first step of signing in with 2FA. The
+// password was correct; the client exchanges the challenge token and a code
+// for a session at /auth/2fa/verify.
+func (a *AuthHandler) startTwoFactorChallenge(c *gin.Context, user models.User) {
+	raw := randomToken("mfa_")
+	err := a.DB.Create(&models.UserToken{
+		UserID:    user.UserID,
+		Purpose:   models.TokenTwoFactor,
+		TokenHash: sharedModels.HashAPIKey(raw),
+		ExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"two_factor_required": true,
+		"challenge_token":     raw,
+		"expires_in":          int(twoFactorChallengeTTL.Seconds()),
+	})
+}
+
+// VerifyTwoFactor completes a sign-in started by SignIn. A challenge allows a
+// few wrong codes before it is burned and the user has to sign in again.
+func (a *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req TwoFactorChallengeRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	now := time.Now()
+	var challenge models.UserToken
+	result := a.DB.Where("token_hash = ? AND purpose = ?", sharedModels.HashAPIKey(req.ChallengeToken), models.TokenTwoFactor).
+		First(&challenge)
+	if result.Error != nil || challenge.UsedAt != nil || now.After(challenge.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in challenge is invalid or has expired"})
+		return
+	}
+
+	var user models.User
+	result = a.DB.Where("user_id = ?", challenge.UserID).First(&user)
+	if result.Error != nil || user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in challenge is invalid or has expired"})
+		return
+	}
+
+	valid, err := a.checkSecondFactor(user, req.TwoFactorCodeRequest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	if !valid {
+		updates := map[string]interface{}{"attempts": gorm.Expr("attempts + 1")}
+		if challenge.Attempts+1 >= maxTwoFactorAttempts {
+			updates["used_at"] = now
+		}
+		a.DB.Model(&challenge).Updates(updates)
+
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	result = a.DB.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", challenge.ID).
+		Update("used_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in challenge is invalid or has expired"})
+		return
+	}
+
+	a.completeSignIn(c, user)
+}
+
+// checkSecondFactor accepts either a TOTP code or an unused recovery code.
+// Each TOTP code and recovery code works only once.
+func (a *AuthHandler) checkSecondFactor(user models.User, req TwoFactorCodeRequest) (bool, error) {
+	if req.Code != "" {
+		step, valid := totp.Validate(user.TOTPSecret, req.Code, time.Now())
+		if !valid {
+			return false, nil
+		}
+
+		result := a.DB.Model(&models.User{}).
+			Where("user_id = ? AND totp_last_step < ?", user.UserID, step).
+			Update("totp_last_step", step)
+		return result.RowsAffected == 1, result.Error
+	}
+
+	if req.RecoveryCode != "" {
+		result := a.DB.Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.UserID, hashRecoveryCode(req.RecoveryCode)).
+			Update("used_at", time.Now())
+		return result.RowsAffected == 1, result.Error
+	}
+
+	return false, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		codes[i] = generateRecoveryCode()
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(codes[i])}
+	}
+
+	return codes, tx.Create(&rows).Error
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	return sharedModels.HashAPIKey(code)
+}
+
+// generateRecoveryCode returns a code like "k3j9a-x7m2q".
+func generateRecoveryCode() string {
+	randomBytes := make([]byte, 10)
+	rand.Read(randomBytes)
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(randomBytes))[:10]
+	return code[:5] + "-" + code[5:]
+}
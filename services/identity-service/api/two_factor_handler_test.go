@@ -0,0 +1,139 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/identity-service/middleware"
+	"github.com/k1ngalph0x/atlas/services/identity-service/totp"
+)
+
+func setupTwoFactorRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	db := setupTestDB(t)
+	h, r := setupRouter(db)
+	r.POST("/auth/2fa/verify", h.VerifyTwoFactor)
+	authed := r.Group("/auth/2fa", middleware.NewAuthMiddleware(h.Keys, db).RequireAuth())
+	authed.POST("/setup", h.SetupTwoFactor)
+	authed.POST("/enable", h.EnableTwoFactor)
+	authed.POST("/disable", h.DisableTwoFactor)
+	return r
+}
+
+func postAuthed(t *testing.T, r *gin.Engine, path string, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func codeAt(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enableTwoFactor signs up and enables 2FA, returning the secret and the
+// recovery codes.
+func enableTwoFactor(t *testing.T, r *gin.Engine) (string, []any) {
+	t.Helper()
+	w := post(t, r, "/auth/signup", map[string]any{"email": "jane@example.com", "password": "password123"})
+	token := decodeBody(t, w)["token"].(string)
+
+	w = postAuthed(t, r, "/auth/2fa/setup", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("setup: expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+	setup := decodeBody(t, w)
+	secret := setup["secret"].(string)
+	if uri, _ := setup["otpauth_uri"].(string); len(uri) < 15 || uri[:15] != "otpauth://totp/" {
+		t.Errorf("unexpected otpauth uri %q", uri)
+	}
+
+	if w := postAuthed(t, r, "/auth/2fa/enable", token, map[string]any{"code": "000000"}); w.Code != http.StatusBadRequest {
+		t.Errorf("wrong code: expected 400, got %d", w.Code)
+	}
+
+	w = postAuthed(t, r, "/auth/2fa/enable", token, map[string]any{"code": codeAt(t, secret, 0)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("enable: expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+	codes := decodeBody(t, w)["recovery_codes"].([]any)
+	if len(codes) != 10 {
+		t.Fatalf("expected 10 recovery codes, got %d", len(codes))
+	}
+
+	return secret, codes
+}
+
+func challenge(t *testing.T, r *gin.Engine) string {
+	t.Helper()
+	w := post(t, r, "/auth/signin", map[string]any{"email": "jane@example.com", "password": "password123"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("signin: expected 200, got %d", w.Code)
+	}
+	body := decodeBody(t, w)
+	if body["two_factor_required"] != true || body["token"] != nil {
+		t.Fatalf("expected a challenge instead of a session, got %v", body)
+	}
+	return body["challenge_token"].(string)
+}
+
+func TestTwoFactor_SignIn(t *testing.T) {
+	r := setupTwoFactorRouter(t)
+	secret, recovery := enableTwoFactor(t, r)
+
+	token := challenge(t, r)
+	if w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "code": codeAt(t, secret, -1)}); w.Code != http.StatusUnauthorized {
+		t.Errorf("code older than the last one used: expected 401, got %d", w.Code)
+	}
+
+	w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "code": codeAt(t, secret, 1)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify: expected 200, got %d — body: %s", w.Code, w.Body.String())
+	}
+	if body := decodeBody(t, w); body["token"] == nil || body["refresh_token"] == nil {
+		t.Errorf("expected a token pair, got %v", body)
+	}
+	if w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "code": codeAt(t, secret, 1)}); w.Code != http.StatusUnauthorized {
+		t.Errorf("used challenge: expected 401, got %d", w.Code)
+	}
+
+	token = challenge(t, r)
+	if w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "recovery_code": recovery[0]}); w.Code != http.StatusOK {
+		t.Fatalf("recovery code: expected 200, got %d", w.Code)
+	}
+	token = challenge(t, r)
+	if w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "recovery_code": recovery[0]}); w.Code != http.StatusUnauthorized {
+		t.Errorf("used recovery code: expected 401, got %d", w.Code)
+	}
+}
+
+func TestTwoFactor_ChallengeAttempts(t *testing.T) {
+	r := setupTwoFactorRouter(t)
+	_, recovery := enableTwoFactor(t, r)
+
+	token := challenge(t, r)
+	for i := 0; i < 5; i++ {
+		if w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "code": "000000"}); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i, w.Code)
+		}
+	}
+
+	w := post(t, r, "/auth/2fa/verify", map[string]any{"challenge_token": token, "recovery_code": recovery[0]})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("burned challenge: expected 401, got %d", w.Code)
+	}
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -9,10 +12,39 @@ import (
 type Config struct {
 	DB PostgresConfig
 	TOKEN TokenConfig
+	CORS CorsConfig
+	KAFKA KafkaConfig
+	MAIL MailConfig
+	ACCOUNT AccountConfig
+}
+
+type MailConfig struct{
+	SmtpHost string
+	SmtpPort string
+	SmtpUsername string
+	SmtpPassword string
+	From string
+	OutboxDir string
+}
+
+type AccountConfig struct{
+	AppUrl string
+	RequireVerifiedEmail bool
+}
+
+type CorsConfig struct{
+	AllowedOrigins []string
+}
+
+type KafkaConfig struct{
+	Brokers []string
 }
 
 type TokenConfig struct{
-	JwtKey string
+	SigningKeyFile string
+	PreviousKeyFiles []string
+	AccessTokenTTL time.Duration
+	RefreshTokenTTL time.Duration
 }
 
 type PostgresConfig struct {
@@ -41,10 +73,73 @@ func LoadConfig() (*Config, error) {
 		},
 
 		TOKEN: TokenConfig{
-			JwtKey: os.Getenv("JwtKey"),
+			SigningKeyFile: os.Getenv("JWT_SIGNING_KEY_FILE"),
+			AccessTokenTTL: 15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+
+		CORS: CorsConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
+		},
+
+		KAFKA: KafkaConfig{
+			Brokers: strings.Split(os.Getenv("KAFKA_BROKERS"), ","),
+		},
+
+		MAIL: MailConfig{
+			SmtpHost: os.Getenv("SMTP_HOST"),
+			SmtpPort: "587",
+			SmtpUsername: os.Getenv("SMTP_USERNAME"),
+			SmtpPassword: os.Getenv("SMTP_PASSWORD"),
+			From: "Atlas <no-reply@atlas.local>",
+			OutboxDir: os.Getenv("MAIL_OUTBOX_DIR"),
+		},
+
+		ACCOUNT: AccountConfig{
+			AppUrl: "http://localhost:5173",
 		},
 	}
 
+	origins := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if origins != ""{
+		config.CORS.AllowedOrigins = strings.Split(origins, ",")
+	}
+
+	previous := os.Getenv("JWT_PREVIOUS_KEY_FILES")
+	if previous != ""{
+		config.TOKEN.PreviousKeyFiles = strings.Split(previous, ",")
+	}
+
+	accessTTL, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL_MINUTES"))
+	if err == nil && accessTTL > 0{
+		config.TOKEN.AccessTokenTTL = time.Duration(accessTTL) * time.Minute
+	}
+
+	refreshTTL, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_TTL_DAYS"))
+	if err == nil && refreshTTL > 0{
+		config.TOKEN.RefreshTokenTTL = time.Duration(refreshTTL) * 24 * time.Hour
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort != ""{
+		config.MAIL.SmtpPort = smtpPort
+	}
+
+	from := os.Getenv("MAIL_FROM")
+	if from != ""{
+		config.MAIL.From = from
+	}
+
+	appUrl := os.Getenv("APP_URL")
+	if appUrl != ""{
+		config.ACCOUNT.AppUrl = strings.TrimRight(appUrl, "/")
+	}
+
+	requireVerified, err := strconv.ParseBool(os.Getenv("REQUIRE_EMAIL_VERIFICATION"))
+	if err == nil{
+		config.ACCOUNT.RequireVerifiedEmail = requireVerified
+	}
+
 	return config, nil
 
 }
\ No newline at end of file
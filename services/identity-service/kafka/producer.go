@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/identity-service/config"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"github.com/segmentio/kafka-go"
+)
+
+const publishTimeout = 5 * time.Second
+
+type KeyEventPublisher struct {
+	Writer *kafka.Writer
+}
+
+func NewKeyEventPublisher(config *config.Config) (*KeyEventPublisher, error) {
+	conn, err := kafka.Dial("tcp", config.KAFKA.Brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.CreateTopics(kafka.TopicConfig{
+		Topic:             sharedModels.APIKeyEventsTopic,
+		NumPartitions:     1,
+		ReplicationFactor: 1,
+	})
+	if err != nil {
+		log.Printf("Topic creation warning: %v", err)
+	}
+
+	return &KeyEventPublisher{
+		Writer: &kafka.Writer{
+			Addr:     kafka.TCP(config.KAFKA.Brokers...),
+			Topic:    sharedModels.APIKeyEventsTopic,
+			Balancer: &kafka.Hash{},
+		},
+	}, nil
+}
+
+func (p *KeyEventPublisher) PublishKeyEvent(event sharedModels.APIKeyEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	return p.Writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.ProjectID),
+		Value: payload,
+	})
+}
@@ -0,0 +1,160 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
+)
+
+type Key struct {
+	JWK     jwks.Key
+	Private crypto.Signer
+	Method  jwt.SigningMethod
+}
+
+// KeySet signs with the active key and publishes every key, so tokens signed
+// before a rotation keep verifying until the previous key is dropped.
+type KeySet struct {
+	Active *Key
+	keys   map[string]*Key
+}
+
+// Load reads the active key and any previous keys from PEM files. Without an
+// active key file it falls back to a generated Ed25519 key, which is only
+// suitable for development since tokens stop verifying after a restart.
+func Load(activeFile string, previousFiles []string) (*KeySet, error) {
+	var active *Key
+	var err error
+	if strings.TrimSpace(activeFile) == "" {
+		active, err = Generate()
+	} else {
+		active, err = ReadFile(activeFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{
+		Active: active,
+		keys:   map[string]*Key{active.JWK.Kid: active},
+	}
+
+	for _, file := range previousFiles {
+		if strings.TrimSpace(file) == "" {
+			continue
+		}
+
+		key, err := ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		set.keys[key.JWK.Kid] = key
+	}
+
+	return set, nil
+}
+
+func Generate() (*Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey(private)
+}
+
+func ReadFile(path string) (*Key, error) {
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, parsed)
+	}
+
+	return newKey(signer)
+}
+
+func newKey(private crypto.Signer) (*Key, error) {
+	var method jwt.SigningMethod
+	switch k := private.(type) {
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", private)
+	}
+
+	jwk, err := jwks.NewKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{JWK: jwk, Private: private, Method: method}, nil
+}
+
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.Active.Method, claims)
+	token.Header["kid"] = s.Active.JWK.Kid
+
+	return token.SignedString(s.Active.Private)
+}
+
+func (s *KeySet) JWKS() jwks.Set {
+	set := jwks.Set{Keys: []jwks.Key{s.Active.JWK}}
+	for kid, key := range s.keys {
+		if kid != s.Active.JWK.Kid {
+			set.Keys = append(set.Keys, key.JWK)
+		}
+	}
+
+	return set
+}
+
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, jwks.ErrUnknownKey
+	}
+
+	public := key.Private.Public()
+	return public, jwks.MatchAlg(token.Method.Alg(), public)
+}
+
+func (s *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, s.Keyfunc, jwt.WithValidMethods([]string{jwks.AlgEdDSA, jwks.AlgRS256}))
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/k1ngalph0x/atlas/services/identity-service/api"
 	"github.com/k1ngalph0x/atlas/services/identity-service/config"
 	"github.com/k1ngalph0x/atlas/services/identity-service/db"
+	"github.com/k1ngalph0x/atlas/services/identity-service/kafka"
 	"github.com/k1ngalph0x/atlas/services/identity-service/middleware"
 	identityModels "github.com/k1ngalph0x/atlas/services/identity-service/models"
+	sharedAuth "github.com/k1ngalph0x/atlas/shared/auth"
 	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
 )
 
@@ -29,6 +31,21 @@ func main() {
 		log.Fatalf("Failed to migrate user table: %v", err)
 	}
 
+	err = conn.AutoMigrate(&identityModels.Session{}, &identityModels.RefreshToken{})
+	if err != nil{
+		log.Fatalf("Failed to migrate session tables: %v", err)
+	}
+
+	err = conn.AutoMigrate(&identityModels.UserToken{})
+	if err != nil{
+		log.Fatalf("Failed to migrate user token table: %v", err)
+	}
+
+	err = conn.AutoMigrate(&identityModels.RecoveryCode{})
+	if err != nil{
+		log.Fatalf("Failed to migrate recovery code table: %v", err)
+	}
+
 	err = conn.AutoMigrate(&identityModels.Organization{})
 	if err != nil{
 		log.Fatalf("Failed to migrate organization table: %v", err)
@@ -39,33 +56,72 @@ func main() {
 		log.Fatalf("Failed to migrate Project table: %v", err)
 	}
 
+	err = conn.AutoMigrate(&identityModels.Membership{}, &identityModels.Invitation{})
+	if err != nil{
+		log.Fatalf("Failed to migrate membership tables: %v", err)
+	}
+
+	err = api.BackfillOwnerMemberships(conn)
+	if err != nil{
+		log.Fatalf("Failed to backfill organization owners: %v", err)
+	}
+
+	err = conn.AutoMigrate(&sharedModels.APIKey{})
+	if err != nil{
+		log.Fatalf("Failed to migrate API key table: %v", err)
+	}
+
+	err = api.BackfillProjectKeys(conn)
+	if err != nil{
+		log.Fatalf("Failed to backfill project API keys: %v", err)
+	}
+
+	err = conn.AutoMigrate(&sharedModels.ProjectLimit{})
+	if err != nil{
+		log.Fatalf("Failed to migrate project limits table: %v", err)
+	}
+
 	authHandler := api.NewAuthHandler(conn, config)
-	authMiddleware := middleware.NewAuthMiddleware(config.TOKEN.JwtKey)
-	projectHandler := api.NewProjectHandler(conn, config)
+	authMiddleware := middleware.NewAuthMiddleware(authHandler.Keys, conn)
+	var keyEvents api.KeyEventPublisher
+	publisher, err := kafka.NewKeyEventPublisher(config)
+	if err != nil{
+		log.Printf("API key events disabled: %v", err)
+	} else {
+		keyEvents = publisher
+	}
+
+	projectHandler := api.NewProjectHandler(conn, config, keyEvents)
 
 	router := gin.Default()
 	router.Use(gin.Logger())
 
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	router.Use(sharedAuth.CORS(config.CORS.AllowedOrigins))
+
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 
 	auth := router.Group("/auth")
 	{
 		auth.POST("/signup", authHandler.SignUp)
 		auth.POST("/signin", authHandler.SignIn)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/logout", authHandler.Logout)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.POST("/2fa/verify", authHandler.VerifyTwoFactor)
 	}
 
 	router.Use(authMiddleware.RequireAuth())
+	router.POST("/auth/resend-verification", authHandler.ResendVerification)
+
+	twoFactor := router.Group("/auth/2fa")
+	{
+		twoFactor.POST("/setup", authHandler.SetupTwoFactor)
+		twoFactor.POST("/enable", authHandler.EnableTwoFactor)
+		twoFactor.POST("/disable", authHandler.DisableTwoFactor)
+		twoFactor.POST("/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	}
 	project := router.Group("/project")
 	{
 		project.POST("/create-organization", projectHandler.CreateOrganization)
@@ -74,6 +130,34 @@ func main() {
 		project.GET("/projects", projectHandler.GetProjects)  
 	}
 
+	organization := router.Group("/organizations/:org_id")
+	{
+		organization.GET("/members", projectHandler.GetMembers)
+		organization.PUT("/members/:user_id", projectHandler.UpdateMemberRole)
+		organization.DELETE("/members/:user_id", projectHandler.RemoveMember)
+		organization.POST("/invitations", projectHandler.CreateInvitation)
+		organization.GET("/invitations", projectHandler.GetInvitations)
+		organization.DELETE("/invitations/:invitation_id", projectHandler.RevokeInvitation)
+	}
+
+	keys := router.Group("/projects/:project_id/keys")
+	{
+		keys.GET("", projectHandler.GetAPIKeys)
+		keys.POST("", projectHandler.CreateAPIKey)
+		keys.POST("/:key_id/rotate", projectHandler.RotateAPIKey)
+		keys.DELETE("/:key_id", projectHandler.RevokeAPIKey)
+	}
+
+	router.GET("/projects/:project_id/limits", projectHandler.GetLimits)
+	router.PUT("/projects/:project_id/limits", projectHandler.UpdateLimits)
+
+	invitations := router.Group("/invitations")
+	{
+		invitations.GET("", projectHandler.GetMyInvitations)
+		invitations.POST("/:token/accept", projectHandler.AcceptInvitation)
+		invitations.POST("/:token/decline", projectHandler.DeclineInvitation)
+	}
+
 	fmt.Println("Running Identity service")
 	router.Run(":8080")
 
@@ -1,22 +1,25 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/k1ngalph0x/atlas/services/identity-service/api"
+	"github.com/k1ngalph0x/atlas/services/identity-service/keys"
+	"github.com/k1ngalph0x/atlas/shared/auth"
+	"gorm.io/gorm"
 )
 
 type AuthMiddleware struct {
-	JwtKey string
+	Keys *keys.KeySet
+	DB   *gorm.DB
 }
 
-func NewAuthMiddleware(jwtKey string) *AuthMiddleware {
+func NewAuthMiddleware(keySet *keys.KeySet, db *gorm.DB) *AuthMiddleware {
 	return &AuthMiddleware{
-		JwtKey: jwtKey,
+		Keys: keySet,
+		DB:   db,
 	}
 }
 func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc{
@@ -36,14 +39,7 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc{
 		}
 
 		tokenString := parts[1]
-		token, err := jwt.ParseWithClaims(tokenString, &api.Claims{}, func(token *jwt.Token)(interface{}, error){
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok{
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-
-			return []byte(a.JwtKey), nil
-		})
+		token, err := a.Keys.Parse(tokenString, &api.Claims{})
 
 		if err != nil || !token.Valid{
 			c.JSON(http.StatusUnauthorized, gin.H{"error":"Invalid or expired token"})
@@ -58,6 +54,18 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc{
 			return 
 		}
 
+		active, err := auth.SessionActive(a.DB, claims.SessionID)
+		if err != nil{
+			c.JSON(http.StatusInternalServerError, gin.H{"error":"Failed to verify session"})
+			c.Abort()
+			return 
+		}
+		if !active{
+			c.JSON(http.StatusUnauthorized, gin.H{"error":"Session has been revoked"})
+			c.Abort()
+			return 
+		}
+
 		c.Set("user_id", claims.UserId)
 		c.Set("email", claims.Email)
 
@@ -11,6 +11,10 @@ type User struct {
 	UserID    string `gorm:"type:uuid;primaryKey" json:"id"`
 	Email     string `gorm:"unique;not null" json:"email"`
 	Password  string `gorm:"not null" json:"-"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPSecret string `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at"`
+	TOTPLastStep int64 `gorm:"not null;default:0" json:"-"`
 	CreatedAt time.Time	`gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -23,6 +27,92 @@ type Organization struct{
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+type Membership struct {
+	ID             string    `gorm:"type:uuid;primaryKey" json:"id"`
+	OrganizationID string    `gorm:"type:uuid;not null;uniqueIndex:idx_org_user" json:"organization_id"`
+	UserID         string    `gorm:"type:uuid;not null;uniqueIndex:idx_org_user;index" json:"user_id"`
+	Role           string    `gorm:"not null" json:"role"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type Invitation struct {
+	ID             string     `gorm:"type:uuid;primaryKey" json:"id"`
+	OrganizationID string     `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Email          string     `gorm:"not null;index" json:"email"`
+	Role           string     `gorm:"not null" json:"role"`
+	TokenHash      string     `gorm:"not null;uniqueIndex" json:"-"`
+	InvitedBy      string     `gorm:"type:uuid;not null" json:"invited_by"`
+	Status         string     `gorm:"not null;default:'pending'" json:"status"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	RespondedAt    *time.Time `json:"responded_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+	TokenTwoFactor     = "two_factor"
+)
+
+// UserToken is a single-use token for one of three purposes: verifying a
+// user's email, resetting their password, or completing a two-factor sign-in.
+// Email and password tokens are mailed to the user. A two_factor token is the
+// sign-in challenge returned by signin; it is never mailed, and Attempts counts
+// the wrong codes tried against it so the challenge can be burned at the limit.
+type UserToken struct {
+	ID        string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   string     `gorm:"type:varchar(32);not null" json:"purpose"`
+	TokenHash string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	Attempts  int        `gorm:"not null;default:0" json:"-"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the
+// user has lost their authenticator.
+type RecoveryCode struct {
+	ID        string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(128);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Session is one sign-in. Its refresh tokens form a family: each refresh
+// replaces the token, and revoking the session ends every token in it.
+type Session struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        string     `gorm:"type:uuid;primaryKey" json:"id"`
+	SessionID string     `gorm:"type:uuid;not null;index" json:"session_id"`
+	TokenHash string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
 
 func(u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.UserID == ""{
@@ -37,3 +127,45 @@ func(o *Organization) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func(m *Membership) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == ""{
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func(i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == ""{
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func(s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == ""{
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func(r *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == ""{
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func(t *UserToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == ""{
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func(r *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == ""{
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/ingestion-service/kafka"
+)
+
+const (
+	maxBatchSize  = 100
+	maxBatchBytes = 5 << 20
+)
+
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func validateEvent(event *Event) error {
+	if strings.TrimSpace(event.Message) == "" {
+		return errors.New("message is required")
+	}
+
+	if strings.TrimSpace(event.Level) == "" {
+		return errors.New("level is required")
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	return nil
+}
+
+func decodeBatch(c *gin.Context) ([]json.RawMessage, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(c.ContentType(), "application/x-ndjson") {
+		var items []json.RawMessage
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 64*1024), maxBatchBytes)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			items = append(items, json.RawMessage(append([]byte(nil), line...)))
+		}
+		return items, scanner.Err()
+	}
+
+	var items []json.RawMessage
+	err = json.Unmarshal(body, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func IngestBatch(c *gin.Context) {
+	projectID := c.GetString("project_id")
+
+	items, err := decodeBatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch payload"})
+		return
+	}
+
+	if len(items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Batch is empty"})
+		return
+	}
+
+	if len(items) > maxBatchSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Batch exceeds %d events", maxBatchSize)})
+		return
+	}
+
+	results := make([]BatchResult, len(items))
+	var payloads [][]byte
+	var accepted []int
+
+	for i, item := range items {
+		results[i] = BatchResult{Index: i, Status: "rejected"}
+
+		var event Event
+		err := json.Unmarshal(item, &event)
+		if err != nil {
+			results[i].Error = "invalid event payload"
+			continue
+		}
+
+		err = validateEvent(&event)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		event.ProjectID = projectID
+		payload, err := json.Marshal(event)
+		if err != nil {
+			results[i].Error = "internal server error"
+			continue
+		}
+
+		payloads = append(payloads, payload)
+		accepted = append(accepted, i)
+	}
+
+	if len(payloads) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"accepted": 0,
+			"rejected": len(items),
+			"results":  results,
+		})
+		return
+	}
+
+	if !allow(c, len(payloads)) {
+		return
+	}
+
+	err = kafka.PublishBatch(projectID, payloads)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish to kafka"})
+		return
+	}
+
+	for _, i := range accepted {
+		results[i].Status = "accepted"
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"accepted": len(accepted),
+		"rejected": len(items) - len(accepted),
+		"results":  results,
+	})
+}
@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	handler "github.com/k1ngalph0x/atlas/services/ingestion-service/api"
+	"github.com/k1ngalph0x/atlas/services/ingestion-service/ratelimit"
+)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/ingest/events/batch", handler.IngestBatch)
+	return r
+}
+
+func postBatch(t *testing.T, r *gin.Engine, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/ingest/events/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var out map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return out
+}
+
+func TestIngestBatch_InvalidPayload(t *testing.T) {
+	w := postBatch(t, setupRouter(), "application/json", `{"message": "not an array"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestIngestBatch_Empty(t *testing.T) {
+	w := postBatch(t, setupRouter(), "application/json", `[]`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestIngestBatch_TooLarge(t *testing.T) {
+	items := make([]string, 101)
+	for i := range items {
+		items[i] = `{"level":"error","message":"boom"}`
+	}
+
+	w := postBatch(t, setupRouter(), "application/json", "["+strings.Join(items, ",")+"]")
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestIngestBatch_RejectsEachInvalidEvent(t *testing.T) {
+	w := postBatch(t, setupRouter(), "application/json", `[{"level":"error"}, "garbage", {"message":"no level"}]`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d — body: %s", w.Code, w.Body.String())
+	}
+
+	body := decodeBody(t, w)
+	if body["rejected"] != float64(3) {
+		t.Errorf("expected 3 rejected, got %v", body["rejected"])
+	}
+
+	results, ok := body["results"].([]any)
+	if !ok || len(results) != 3 {
+		t.Fatalf("expected 3 results, got %v", body["results"])
+	}
+	for _, r := range results {
+		if r.(map[string]any)["status"] != "rejected" {
+			t.Errorf("expected rejected result, got %v", r)
+		}
+	}
+}
+
+func TestIngestBatch_NDJSON(t *testing.T) {
+	body := "{\"level\":\"error\"}\n\nnot-json\n"
+	w := postBatch(t, setupRouter(), "application/x-ndjson", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	out := decodeBody(t, w)
+	if out["rejected"] != float64(2) {
+		t.Errorf("expected blank lines to be skipped and 2 rejected, got %v", out["rejected"])
+	}
+}
+
+func TestIngestBatch_RateLimited(t *testing.T) {
+	handler.Limiter = ratelimit.NewLimiter(nil, ratelimit.Limits{MonthlyQuota: 1}, 10)
+	defer func() { handler.Limiter = nil }()
+
+	w := postBatch(t, setupRouter(), "application/json", `[{"level":"error","message":"a"},{"level":"error","message":"b"}]`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d — body: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+	if body := decodeBody(t, w); body["reason"] != ratelimit.ReasonQuotaExceeded {
+		t.Errorf("expected quota_exceeded, got %v", body["reason"])
+	}
+}
+
+func TestIngestBatch_SpikeSampled(t *testing.T) {
+	handler.Limiter = ratelimit.NewLimiter(nil, ratelimit.Limits{SpikeProtection: true}, 10)
+	defer func() { handler.Limiter = nil }()
+
+	// Build a baseline of 100 events a minute, then flood the current minute
+	// so far past the threshold that every further request is sampled out.
+	now := time.Now()
+	for i := 6; i > 0; i-- {
+		handler.Limiter.Allow("", "", 100, now.Add(-time.Duration(i)*time.Minute))
+	}
+	handler.Limiter.Allow("", "", 1e12, now)
+
+	w := postBatch(t, setupRouter(), "application/json", `[{"level":"error","message":"a"},{"level":"error","message":"b"}]`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 so the SDK does not retry, got %d — body: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") != "" {
+		t.Error("expected no Retry-After header")
+	}
+	body := decodeBody(t, w)
+	if body["reason"] != ratelimit.ReasonSpikeProtection || body["dropped"] != float64(2) {
+		t.Errorf("expected 2 events dropped by spike protection, got %v", body)
+	}
+}
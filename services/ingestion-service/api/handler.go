@@ -15,6 +15,9 @@ type Event struct {
 	Level string `json:"level"`
 	Message string  `json:"message"`
 	StackTrace string `json:"stack_trace"`
+	Fingerprint []string `json:"fingerprint,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+	Release string `json:"release,omitempty"`
 }
 
 func Ingest(c *gin.Context){
@@ -28,6 +31,10 @@ func Ingest(c *gin.Context){
 		return
 	}
 
+	if !allow(c, 1){
+		return
+	}
+
 	event.ProjectID = projectID 
 	payload, err := json.Marshal(event)
 	if err != nil{
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/ingestion-service/ratelimit"
+)
+
+// Limiter is set by main; when nil, ingestion is not limited.
+var Limiter *ratelimit.Limiter
+
+var limitMessages = map[string]string{
+	ratelimit.ReasonRateLimited:     "Rate limit exceeded",
+	ratelimit.ReasonQuotaExceeded:   "Monthly event quota exceeded",
+	ratelimit.ReasonSpikeProtection: "Event spike detected, events were sampled out",
+}
+
+// allow checks n events against the project's limits and writes a 429 with
+// Retry-After when they are rejected. Events sampled out by spike protection
+// get a 202 instead: they are dropped on purpose, and a 429 would make the SDK
+// retry and spool them, adding to the spike.
+func allow(c *gin.Context, n int) bool {
+	if Limiter == nil {
+		return true
+	}
+
+	decision := Limiter.Allow(c.GetString("project_id"), c.GetString("api_key_id"), n, time.Now())
+	if decision.Allowed {
+		return true
+	}
+
+	if decision.Reason == ratelimit.ReasonSpikeProtection {
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  limitMessages[decision.Reason],
+			"reason":  decision.Reason,
+			"dropped": n,
+		})
+		return false
+	}
+
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       limitMessages[decision.Reason],
+		"reason":      decision.Reason,
+		"retry_after": retryAfter,
+	})
+	return false
+}
+
+func Stats(c *gin.Context) {
+	if Limiter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rate limiting is disabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": Limiter.Stats(c.GetString("project_id"), time.Now())})
+}
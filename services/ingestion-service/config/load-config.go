@@ -2,23 +2,43 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	DB PostgresConfig
-	TOKEN TokenConfig
 	KAFKA KafkaConfig
+	CACHE CacheConfig
+	LIMITS LimitsConfig
+	METRICS MetricsConfig
 }
 
-type KafkaConfig struct{
-	Brokers []string
+type MetricsConfig struct{
+	Addr string
+}
+
+type LimitsConfig struct{
+	EventsPerSecond float64
+	Burst int
+	KeyEventsPerSecond float64
+	KeyBurst int
+	MonthlyQuota int64
+	SpikeProtection bool
+	SpikeMultiplier float64
+}
+
+type CacheConfig struct{
+	Size int
+	TTL time.Duration
+	NegativeTTL time.Duration
 }
 
-type TokenConfig struct{
-	JwtKey string
+type KafkaConfig struct{
+	Brokers []string
 }
 
 type PostgresConfig struct {
@@ -46,13 +66,83 @@ func LoadConfig() (*Config, error) {
 			Dbname: os.Getenv("DB_NAME"),
 		},
 
-		TOKEN: TokenConfig{
-			JwtKey: os.Getenv("JwtKey"),
-		},
-
 		KAFKA: KafkaConfig{
 			Brokers: strings.Split(os.Getenv("KAFKA_BROKERS"), ","),
 		},
+
+		CACHE: CacheConfig{
+			Size: 10000,
+			TTL: 5 * time.Minute,
+			NegativeTTL: 30 * time.Second,
+		},
+
+		LIMITS: LimitsConfig{
+			EventsPerSecond: 100,
+			Burst: 500,
+			KeyEventsPerSecond: 50,
+			KeyBurst: 250,
+			SpikeProtection: true,
+			SpikeMultiplier: 10,
+		},
+
+		METRICS: MetricsConfig{
+			Addr: "127.0.0.1:9081",
+		},
+	}
+
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr != ""{
+		config.METRICS.Addr = metricsAddr
+	}
+
+	size, err := strconv.Atoi(os.Getenv("API_KEY_CACHE_SIZE"))
+	if err == nil && size > 0{
+		config.CACHE.Size = size
+	}
+
+	ttl, err := strconv.Atoi(os.Getenv("API_KEY_CACHE_TTL_SECONDS"))
+	if err == nil && ttl > 0{
+		config.CACHE.TTL = time.Duration(ttl) * time.Second
+	}
+
+	negativeTTL, err := strconv.Atoi(os.Getenv("API_KEY_NEGATIVE_TTL_SECONDS"))
+	if err == nil && negativeTTL > 0{
+		config.CACHE.NegativeTTL = time.Duration(negativeTTL) * time.Second
+	}
+
+	rate, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_EVENTS_PER_SECOND"), 64)
+	if err == nil && rate >= 0{
+		config.LIMITS.EventsPerSecond = rate
+	}
+
+	burst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST"))
+	if err == nil && burst > 0{
+		config.LIMITS.Burst = burst
+	}
+
+	keyRate, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_KEY_EVENTS_PER_SECOND"), 64)
+	if err == nil && keyRate >= 0{
+		config.LIMITS.KeyEventsPerSecond = keyRate
+	}
+
+	keyBurst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_KEY_BURST"))
+	if err == nil && keyBurst > 0{
+		config.LIMITS.KeyBurst = keyBurst
+	}
+
+	quota, err := strconv.ParseInt(os.Getenv("MONTHLY_EVENT_QUOTA"), 10, 64)
+	if err == nil && quota >= 0{
+		config.LIMITS.MonthlyQuota = quota
+	}
+
+	spike, err := strconv.ParseBool(os.Getenv("SPIKE_PROTECTION"))
+	if err == nil{
+		config.LIMITS.SpikeProtection = spike
+	}
+
+	multiplier, err := strconv.ParseFloat(os.Getenv("SPIKE_MULTIPLIER"), 64)
+	if err == nil && multiplier > 1{
+		config.LIMITS.SpikeMultiplier = multiplier
 	}
 
 	return config, nil
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/ingestion-service/config"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// ConsumeKeyEvents calls invalidate for every key identity-service revokes or
+// rotates. Each instance holds its own cache, so each joins its own consumer
+// group to see every event.
+func ConsumeKeyEvents(config *config.Config, invalidate func(keyHash string)) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     config.KAFKA.Brokers,
+		Topic:       sharedModels.APIKeyEventsTopic,
+		GroupID:     "ingestion-key-cache-" + host,
+		StartOffset: kafka.LastOffset,
+	})
+	defer reader.Close()
+
+	for {
+		msg, err := reader.ReadMessage(context.Background())
+		if err != nil {
+			log.Printf("Failed to read API key event: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		var event sharedModels.APIKeyEvent
+		err = json.Unmarshal(msg.Value, &event)
+		if err != nil {
+			log.Printf("Skipping malformed API key event: %v", err)
+			continue
+		}
+
+		invalidate(event.KeyHash)
+	}
+}
@@ -51,4 +51,16 @@ func Publish(projectID string, message []byte) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func PublishBatch(projectID string, messages [][]byte) error {
+	batch := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		batch[i] = kafka.Message{
+			Key:   []byte(projectID),
+			Value: message,
+		}
+	}
+
+	return Writer.WriteMessages(context.Background(), batch...)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	handler "github.com/k1ngalph0x/atlas/services/ingestion-service/api"
@@ -9,6 +10,8 @@ import (
 	"github.com/k1ngalph0x/atlas/services/ingestion-service/db"
 	"github.com/k1ngalph0x/atlas/services/ingestion-service/kafka"
 	"github.com/k1ngalph0x/atlas/services/ingestion-service/middleware"
+	"github.com/k1ngalph0x/atlas/services/ingestion-service/ratelimit"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
 )
 
 func main() {
@@ -22,9 +25,38 @@ func main() {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
 
+	err = conn.AutoMigrate(&sharedModels.ProjectUsage{})
+	if err != nil{
+		log.Fatalf("Failed to migrate project usage table: %v", err)
+	}
+
 	kafka.InitKafka(config)
 
-	authMiddleware := middleware.NewAuthMiddleware(conn)
+	handler.Limiter = ratelimit.NewLimiter(conn, ratelimit.Limits{
+		EventsPerSecond:    config.LIMITS.EventsPerSecond,
+		Burst:              config.LIMITS.Burst,
+		KeyEventsPerSecond: config.LIMITS.KeyEventsPerSecond,
+		KeyBurst:           config.LIMITS.KeyBurst,
+		MonthlyQuota:       config.LIMITS.MonthlyQuota,
+		SpikeProtection:    config.LIMITS.SpikeProtection,
+	}, config.LIMITS.SpikeMultiplier)
+	go handler.Limiter.Run(10 * time.Second)
+
+	keyCache := middleware.NewKeyCache(config.CACHE.Size, config.CACHE.TTL, config.CACHE.NegativeTTL)
+	authMiddleware := middleware.NewAuthMiddleware(conn, keyCache)
+
+	go kafka.ConsumeKeyEvents(config, keyCache.Invalidate)
+
+	// Metrics are served on a separate listener, loopback only by default, so
+	// they are never reachable through the public ingestion port.
+	metrics := gin.New()
+	metrics.GET("/metrics/api-key-cache", authMiddleware.CacheStats)
+	go func() {
+		err := metrics.Run(config.METRICS.Addr)
+		if err != nil{
+			log.Printf("Metrics listener stopped: %v", err)
+		}
+	}()
 
 	router := gin.Default()
 	router.Use(gin.Logger())
@@ -33,6 +65,8 @@ func main() {
 	api := router.Group("/api")
 	{
 		api.POST("/ingest/events", handler.Ingest)
+		api.POST("/ingest/events/batch", handler.IngestBatch)
+		api.GET("/ingest/stats", handler.Stats)
 	}
 
 	router.Run(":8081")
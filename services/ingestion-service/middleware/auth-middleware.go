@@ -1,20 +1,27 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
 	"gorm.io/gorm"
 )
 
+const lastUsedResolution = time.Minute
+
 type APIKeyMiddleware struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Cache *KeyCache
 }
 
-func NewAuthMiddleware(db *gorm.DB) *APIKeyMiddleware{
+func NewAuthMiddleware(db *gorm.DB, cache *KeyCache) *APIKeyMiddleware{
 	return &APIKeyMiddleware{
-		DB: db,
+		DB:    db,
+		Cache: cache,
 	}
 }
 
@@ -28,18 +35,84 @@ func (a *APIKeyMiddleware) ValidateAPIKey() gin.HandlerFunc{
 		}
 
 		hashedKey := sharedModels.HashAPIKey(apiKey)
+		now := time.Now()
 
-		var project sharedModels.Project
-		//result := a.DB.Where("api_key = ?", apiKey).First(&project)
-		result := a.DB.Where("api_key = ?", hashedKey).First(&project)
-		if result.Error != nil{
+		cached, err := a.lookup(hashedKey, now)
+		if err != nil{
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to validate API key"})
+			c.Abort()
+			return
+		}
+
+		if cached.Key == nil{
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 			c.Abort()
 			return
 		}
 
-		c.Set("project_id", project.ID)
-		c.Set("organization_id", project.OrganizationID)
+		key := *cached.Key
+		if !key.Active(now){
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key has been revoked or expired"})
+			c.Abort()
+			return
+		}
+
+		a.touch(hashedKey, key, now)
+
+		c.Set("api_key_id", key.ID)
+		c.Set("project_id", key.ProjectID)
+		c.Set("organization_id", cached.OrganizationID)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// lookup resolves a hashed key through the cache. Unknown keys are cached as
+// misses; database errors are not cached.
+func (a *APIKeyMiddleware) lookup(hashedKey string, now time.Time) (cachedKey, error) {
+	cached, ok := a.Cache.Get(hashedKey, now)
+	if ok {
+		return cached, nil
+	}
+
+	var key sharedModels.APIKey
+	err := a.DB.Where("key_hash = ?", hashedKey).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		a.Cache.Add(hashedKey, cachedKey{}, now)
+		return cachedKey{}, nil
+	}
+	if err != nil {
+		return cachedKey{}, err
+	}
+
+	var project sharedModels.Project
+	err = a.DB.Where("id = ?", key.ProjectID).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		a.Cache.Add(hashedKey, cachedKey{}, now)
+		return cachedKey{}, nil
+	}
+	if err != nil {
+		return cachedKey{}, err
+	}
+
+	cached = cachedKey{Key: &key, OrganizationID: project.OrganizationID}
+	a.Cache.Add(hashedKey, cached, now)
+	return cached, nil
+}
+
+// touch records last_used_at at most once per lastUsedResolution so busy keys
+// do not cost a write per event.
+func (a *APIKeyMiddleware) touch(hashedKey string, key sharedModels.APIKey, now time.Time) {
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < lastUsedResolution {
+		return
+	}
+
+	a.Cache.MarkUsed(hashedKey, now)
+	err := a.DB.Model(&sharedModels.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", now).Error
+	if err != nil {
+		log.Printf("Failed to update last_used_at for key %s: %v", key.ID, err)
+	}
+}
+
+func (a *APIKeyMiddleware) CacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"api_key_cache": a.Cache.Stats()})
+}
@@ -0,0 +1,147 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/ingestion-service/middleware"
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ingestion.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&sharedModels.Project{}, &sharedModels.APIKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func createKey(t *testing.T, db *gorm.DB, projectID string, modify func(*sharedModels.APIKey)) string {
+	t.Helper()
+	key, raw := sharedModels.NewAPIKey(projectID, "test", "")
+	if modify != nil {
+		modify(key)
+	}
+	if err := db.Create(key).Error; err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	return raw
+}
+
+func createProject(t *testing.T, db *gorm.DB) (*sharedModels.Project, string) {
+	t.Helper()
+	project, raw := sharedModels.NewProject("web", "00000000-0000-0000-0000-000000000001")
+	if err := db.Create(project).Error; err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+	return project, raw
+}
+
+func setupRouter(db *gorm.DB, cache *middleware.KeyCache) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.NewAuthMiddleware(db, cache).ValidateAPIKey())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("project_id"))
+	})
+	return r
+}
+
+func get(r *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	db := setupTestDB(t)
+	project, legacy := createProject(t, db)
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	active := createKey(t, db, project.ID, nil)
+	grace := createKey(t, db, project.ID, func(k *sharedModels.APIKey) { k.ExpiresAt = &future })
+	expired := createKey(t, db, project.ID, func(k *sharedModels.APIKey) { k.ExpiresAt = &past })
+	revoked := createKey(t, db, project.ID, func(k *sharedModels.APIKey) { k.RevokedAt = &past })
+
+	r := setupRouter(db, middleware.NewKeyCache(100, time.Minute, time.Minute))
+
+	cases := map[string]struct {
+		key  string
+		code int
+	}{
+		"active":          {active, http.StatusOK},
+		"in grace period": {grace, http.StatusOK},
+		"expired":         {expired, http.StatusUnauthorized},
+		"revoked":         {revoked, http.StatusUnauthorized},
+		"unknown":         {"atlas_unknown", http.StatusUnauthorized},
+		"project column":  {legacy, http.StatusUnauthorized},
+	}
+	for name, tc := range cases {
+		w := get(r, tc.key)
+		if w.Code != tc.code {
+			t.Errorf("%s: expected %d, got %d", name, tc.code, w.Code)
+		}
+		if tc.code == http.StatusOK && w.Body.String() != project.ID {
+			t.Errorf("%s: expected project %s, got %q", name, project.ID, w.Body.String())
+		}
+	}
+
+	var used sharedModels.APIKey
+	db.Where("key_hash = ?", sharedModels.HashAPIKey(active)).First(&used)
+	if used.LastUsedAt == nil {
+		t.Error("expected last_used_at to be recorded")
+	}
+}
+
+func TestValidateAPIKey_Cache(t *testing.T) {
+	db := setupTestDB(t)
+	project, _ := createProject(t, db)
+	raw := createKey(t, db, project.ID, nil)
+	hash := sharedModels.HashAPIKey(raw)
+
+	cache := middleware.NewKeyCache(100, time.Hour, time.Hour)
+	r := setupRouter(db, cache)
+
+	if w := get(r, raw); w.Code != http.StatusOK {
+		t.Fatalf("first request: expected 200, got %d", w.Code)
+	}
+
+	now := time.Now()
+	db.Model(&sharedModels.APIKey{}).Where("key_hash = ?", hash).Update("revoked_at", now)
+	if w := get(r, raw); w.Code != http.StatusOK {
+		t.Errorf("cached key: expected 200 before invalidation, got %d", w.Code)
+	}
+
+	cache.Invalidate(hash)
+	if w := get(r, raw); w.Code != http.StatusUnauthorized {
+		t.Errorf("invalidated key: expected 401, got %d", w.Code)
+	}
+
+	if w := get(r, "atlas_missing"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown key: expected 401, got %d", w.Code)
+	}
+	if w := get(r, "atlas_missing"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown key again: expected 401, got %d", w.Code)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.NegativeHits != 1 || stats.Misses != 3 || stats.Invalidations != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
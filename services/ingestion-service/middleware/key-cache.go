@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+)
+
+// cachedKey is what ValidateAPIKey needs from a key lookup. Key is nil for
+// hashes that matched no key, so repeated bad keys also skip Postgres.
+type cachedKey struct {
+	Key            *sharedModels.APIKey
+	OrganizationID string
+}
+
+type cacheEntry struct {
+	hash      string
+	value     cachedKey
+	expiresAt time.Time
+}
+
+// KeyCache is an LRU of hashed API key to key lookup. Entries expire after
+// TTL (NegativeTTL for misses) and are dropped early on invalidation events.
+type KeyCache struct {
+	Size        int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+
+	hits          atomic.Uint64
+	negativeHits  atomic.Uint64
+	misses        atomic.Uint64
+	evictions     atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+type CacheStats struct {
+	Size          int     `json:"size"`
+	Capacity      int     `json:"capacity"`
+	Hits          uint64  `json:"hits"`
+	NegativeHits  uint64  `json:"negative_hits"`
+	Misses        uint64  `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`
+	Evictions     uint64  `json:"evictions"`
+	Invalidations uint64  `json:"invalidations"`
+}
+
+func NewKeyCache(size int, ttl time.Duration, negativeTTL time.Duration) *KeyCache {
+	return &KeyCache{
+		Size:        size,
+		TTL:         ttl,
+		NegativeTTL: negativeTTL,
+		order:       list.New(),
+		entries:     map[string]*list.Element{},
+	}
+}
+
+func (k *KeyCache) Get(hash string, now time.Time) (cachedKey, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	el, ok := k.entries[hash]
+	if !ok {
+		k.misses.Add(1)
+		return cachedKey{}, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if !now.Before(entry.expiresAt) {
+		k.remove(el)
+		k.misses.Add(1)
+		return cachedKey{}, false
+	}
+
+	k.order.MoveToFront(el)
+	if entry.value.Key == nil {
+		k.negativeHits.Add(1)
+	} else {
+		k.hits.Add(1)
+	}
+
+	return entry.value, true
+}
+
+func (k *KeyCache) Add(hash string, value cachedKey, now time.Time) {
+	ttl := k.TTL
+	if value.Key == nil {
+		ttl = k.NegativeTTL
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if el, ok := k.entries[hash]; ok {
+		el.Value = &cacheEntry{hash: hash, value: value, expiresAt: now.Add(ttl)}
+		k.order.MoveToFront(el)
+		return
+	}
+
+	k.entries[hash] = k.order.PushFront(&cacheEntry{hash: hash, value: value, expiresAt: now.Add(ttl)})
+	for k.order.Len() > k.Size {
+		k.remove(k.order.Back())
+		k.evictions.Add(1)
+	}
+}
+
+// MarkUsed keeps the cached last_used_at in step with the database so the
+// middleware does not rewrite it on every hit.
+func (k *KeyCache) MarkUsed(hash string, at time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	el, ok := k.entries[hash]
+	if !ok {
+		return
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if entry.value.Key == nil {
+		return
+	}
+
+	key := *entry.value.Key
+	key.LastUsedAt = &at
+	entry.value.Key = &key
+}
+
+func (k *KeyCache) Invalidate(hash string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if el, ok := k.entries[hash]; ok {
+		k.remove(el)
+		k.invalidations.Add(1)
+	}
+}
+
+func (k *KeyCache) Stats() CacheStats {
+	k.mu.Lock()
+	size := k.order.Len()
+	k.mu.Unlock()
+
+	stats := CacheStats{
+		Size:          size,
+		Capacity:      k.Size,
+		Hits:          k.hits.Load(),
+		NegativeHits:  k.negativeHits.Load(),
+		Misses:        k.misses.Load(),
+		Evictions:     k.evictions.Load(),
+		Invalidations: k.invalidations.Load(),
+	}
+
+	lookups := stats.Hits + stats.NegativeHits + stats.Misses
+	if lookups > 0 {
+		stats.HitRate = float64(stats.Hits+stats.NegativeHits) / float64(lookups)
+	}
+
+	return stats
+}
+
+// remove must be called with mu held.
+func (k *KeyCache) remove(el *list.Element) {
+	k.order.Remove(el)
+	delete(k.entries, el.Value.(*cacheEntry).hash)
+}
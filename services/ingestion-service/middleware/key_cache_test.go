@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+)
+
+func TestKeyCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewKeyCache(2, time.Minute, time.Minute)
+	now := time.Now()
+
+	cache.Add("a", cachedKey{Key: &sharedModels.APIKey{ID: "a"}}, now)
+	cache.Add("b", cachedKey{Key: &sharedModels.APIKey{ID: "b"}}, now)
+	cache.Get("a", now)
+	cache.Add("c", cachedKey{Key: &sharedModels.APIKey{ID: "c"}}, now)
+
+	if _, ok := cache.Get("b", now); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := cache.Get("a", now); !ok {
+		t.Error("expected a to survive as recently used")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestKeyCache_Expiry(t *testing.T) {
+	cache := NewKeyCache(10, time.Minute, time.Second)
+	now := time.Now()
+
+	cache.Add("valid", cachedKey{Key: &sharedModels.APIKey{ID: "valid"}}, now)
+	cache.Add("invalid", cachedKey{}, now)
+
+	later := now.Add(2 * time.Second)
+	if _, ok := cache.Get("invalid", later); ok {
+		t.Error("expected negative entry to expire after NegativeTTL")
+	}
+	if _, ok := cache.Get("valid", later); !ok {
+		t.Error("expected positive entry to outlive NegativeTTL")
+	}
+	if _, ok := cache.Get("valid", now.Add(time.Minute)); ok {
+		t.Error("expected positive entry to expire after TTL")
+	}
+}
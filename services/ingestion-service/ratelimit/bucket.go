@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// bucket is a token bucket refilled at rate tokens per second up to burst.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int, now time.Time) *bucket {
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// setLimits applies new limits without refilling the bucket.
+func (b *bucket) setLimits(rate float64, burst int) {
+	b.rate = rate
+	b.burst = float64(burst)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// cost caps n at the burst size so a batch larger than the burst can still
+// get through once the bucket is full.
+func (b *bucket) cost(n int) float64 {
+	return math.Min(float64(n), b.burst)
+}
+
+// wait returns how long until n tokens are available, or 0 if they are now.
+func (b *bucket) wait(n int, now time.Time) time.Duration {
+	b.refill(now)
+
+	missing := b.cost(n) - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	if b.rate <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(missing / b.rate * float64(time.Second))
+}
+
+func (b *bucket) take(n int) {
+	b.tokens -= b.cost(n)
+}
@@ -0,0 +1,421 @@
+package ratelimit
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idleAfter is how long a project or key goes without traffic before its
+// buckets are dropped. By then a bucket has refilled and a spike baseline has
+// decayed to almost nothing, so recreating them loses little.
+const idleAfter = 30 * time.Minute
+
+const (
+	ReasonRateLimited     = "rate_limited"
+	ReasonQuotaExceeded   = "quota_exceeded"
+	ReasonSpikeProtection = "spike_protection"
+)
+
+// Limits are the effective limits for a project. A rate of 0 disables that
+// bucket and a quota of 0 means unlimited.
+type Limits struct {
+	EventsPerSecond    float64 `json:"events_per_second"`
+	Burst              int     `json:"burst"`
+	KeyEventsPerSecond float64 `json:"key_events_per_second"`
+	KeyBurst           int     `json:"key_burst"`
+	MonthlyQuota       int64   `json:"monthly_quota"`
+	SpikeProtection    bool    `json:"spike_protection"`
+}
+
+type Decision struct {
+	Allowed    bool
+	Reason     string
+	RetryAfter time.Duration
+}
+
+type Stats struct {
+	Month          string           `json:"month"`
+	Accepted       int64            `json:"accepted"`
+	Dropped        map[string]int64 `json:"dropped"`
+	QuotaRemaining *int64           `json:"quota_remaining"`
+	Limits         Limits           `json:"limits"`
+	Spike          SpikeStats       `json:"spike"`
+}
+
+type SpikeStats struct {
+	Active            bool    `json:"active"`
+	BaselinePerMinute float64 `json:"baseline_per_minute"`
+	CurrentPerMinute  float64 `json:"current_per_minute"`
+}
+
+// Limiter enforces per-project and per-key token buckets, monthly quotas and
+// spike protection. Buckets and spike baselines are per instance; usage is
+// flushed to project_usages so quotas hold across instances, give or take
+// one flush interval.
+type Limiter struct {
+	DB              *gorm.DB
+	Defaults        Limits
+	SpikeMultiplier float64
+
+	mu       sync.Mutex
+	projects map[string]*projectState
+	keys     map[string]*bucket
+	carry    []sharedModels.ProjectUsage
+	random   func() float64
+}
+
+type projectState struct {
+	limits  Limits
+	bucket  *bucket
+	spike   spike
+	stored  sharedModels.ProjectUsage
+	pending sharedModels.ProjectUsage
+	seen    time.Time
+}
+
+func NewLimiter(db *gorm.DB, defaults Limits, spikeMultiplier float64) *Limiter {
+	return &Limiter{
+		DB:              db,
+		Defaults:        defaults,
+		SpikeMultiplier: spikeMultiplier,
+		projects:        map[string]*projectState{},
+		keys:            map[string]*bucket{},
+		random:          rand.Float64,
+	}
+}
+
+func Month(now time.Time) string {
+	return now.UTC().Format("2006-01")
+}
+
+func nextMonth(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Allow decides whether n events from projectID, sent with keyID, may be
+// ingested, and records the outcome for the project's usage.
+func (l *Limiter) Allow(projectID string, keyID string, n int, now time.Time) Decision {
+	p := l.project(projectID, now)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Evict may have dropped the project since it was looked up.
+	if current, ok := l.projects[projectID]; ok {
+		p = current
+	} else {
+		l.projects[projectID] = p
+	}
+	p.seen = now
+	l.rollMonth(p, now)
+	count := int64(n)
+
+	quota := p.limits.MonthlyQuota
+	if quota > 0 && p.stored.Accepted+p.pending.Accepted+count > quota {
+		p.pending.QuotaExceeded += count
+		return Decision{Reason: ReasonQuotaExceeded, RetryAfter: nextMonth(now).Sub(now)}
+	}
+
+	if p.limits.SpikeProtection {
+		keep := p.spike.keep(n, now, l.SpikeMultiplier)
+		if keep < 1 && l.random() >= keep {
+			p.pending.SpikeDropped += count
+			return Decision{Reason: ReasonSpikeProtection, RetryAfter: p.spike.remaining(now)}
+		}
+	}
+
+	var wait time.Duration
+	project := p.limits.EventsPerSecond > 0
+	if project {
+		wait = p.bucket.wait(n, now)
+	}
+	key := l.keyBucket(keyID, p.limits, now)
+	if key != nil {
+		if keyWait := key.wait(n, now); keyWait > wait {
+			wait = keyWait
+		}
+	}
+	if wait > 0 {
+		p.pending.RateLimited += count
+		return Decision{Reason: ReasonRateLimited, RetryAfter: wait}
+	}
+
+	if project {
+		p.bucket.take(n)
+	}
+	if key != nil {
+		key.take(n)
+	}
+	p.pending.Accepted += count
+	return Decision{Allowed: true}
+}
+
+func (l *Limiter) Stats(projectID string, now time.Time) Stats {
+	p := l.project(projectID, now)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rollMonth(p, now)
+	p.spike.roll(now, l.SpikeMultiplier)
+
+	stats := Stats{
+		Month:    p.stored.Month,
+		Accepted: p.stored.Accepted + p.pending.Accepted,
+		Dropped: map[string]int64{
+			ReasonRateLimited:     p.stored.RateLimited + p.pending.RateLimited,
+			ReasonQuotaExceeded:   p.stored.QuotaExceeded + p.pending.QuotaExceeded,
+			ReasonSpikeProtection: p.stored.SpikeDropped + p.pending.SpikeDropped,
+		},
+		Limits: p.limits,
+		Spike: SpikeStats{
+			Active:            p.limits.SpikeProtection && p.spike.count > p.spike.threshold(l.SpikeMultiplier),
+			BaselinePerMinute: p.spike.baseline,
+			CurrentPerMinute:  p.spike.count,
+		},
+	}
+
+	if p.limits.MonthlyQuota > 0 {
+		remaining := p.limits.MonthlyQuota - stats.Accepted
+		if remaining < 0 {
+			remaining = 0
+		}
+		stats.QuotaRemaining = &remaining
+	}
+
+	return stats
+}
+
+// Run flushes usage, reloads limits and evicts idle buckets every interval.
+func (l *Limiter) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		err := l.Flush(now)
+		if err != nil {
+			log.Printf("Failed to flush ingestion usage: %v", err)
+		}
+		l.Evict(now)
+	}
+}
+
+// Evict drops projects and keys that have seen no traffic for idleAfter, so
+// the maps do not grow with every project and key ever seen. Projects with
+// usage that has not been flushed yet are kept.
+func (l *Limiter) Evict(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for id, p := range l.projects {
+		if now.Sub(p.seen) >= idleAfter && !hasUsage(p.pending) {
+			delete(l.projects, id)
+		}
+	}
+	for id, b := range l.keys {
+		if now.Sub(b.last) >= idleAfter {
+			delete(l.keys, id)
+		}
+	}
+}
+
+// Flush writes pending usage to project_usages and reloads each project's
+// limits and usage, picking up other instances' counts and limit changes.
+func (l *Limiter) Flush(now time.Time) error {
+	if l.DB == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	batch := l.carry
+	l.carry = nil
+	ids := make([]string, 0, len(l.projects))
+	for id, p := range l.projects {
+		ids = append(ids, id)
+		if !hasUsage(p.pending) {
+			continue
+		}
+
+		batch = append(batch, p.pending)
+		p.stored.Accepted += p.pending.Accepted
+		p.stored.RateLimited += p.pending.RateLimited
+		p.stored.QuotaExceeded += p.pending.QuotaExceeded
+		p.stored.SpikeDropped += p.pending.SpikeDropped
+		p.pending = sharedModels.ProjectUsage{ProjectID: id, Month: p.pending.Month}
+	}
+	l.mu.Unlock()
+
+	var firstErr error
+	for i, usage := range batch {
+		err := l.DB.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "project_id"}, {Name: "month"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"accepted":       gorm.Expr("project_usages.accepted + ?", usage.Accepted),
+				"rate_limited":   gorm.Expr("project_usages.rate_limited + ?", usage.RateLimited),
+				"quota_exceeded": gorm.Expr("project_usages.quota_exceeded + ?", usage.QuotaExceeded),
+				"spike_dropped":  gorm.Expr("project_usages.spike_dropped + ?", usage.SpikeDropped),
+				"updated_at":     now,
+			}),
+		}).Create(&batch[i]).Error
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			l.mu.Lock()
+			l.carry = append(l.carry, usage)
+			l.mu.Unlock()
+		}
+	}
+
+	month := Month(now)
+	for _, id := range ids {
+		limits := l.loadLimits(id)
+		usage, err := l.loadUsage(id, month)
+
+		l.mu.Lock()
+		p, ok := l.projects[id]
+		if !ok {
+			l.mu.Unlock()
+			continue
+		}
+		p.limits = limits
+		p.bucket.setLimits(limits.EventsPerSecond, limits.Burst)
+		if err == nil && p.stored.Month == month {
+			p.stored = usage
+		}
+		l.mu.Unlock()
+	}
+
+	return firstErr
+}
+
+func hasUsage(u sharedModels.ProjectUsage) bool {
+	return u.Accepted != 0 || u.RateLimited != 0 || u.QuotaExceeded != 0 || u.SpikeDropped != 0
+}
+
+// project returns the state for projectID, loading its limits and usage the
+// first time it is seen. The loads happen outside mu.
+func (l *Limiter) project(projectID string, now time.Time) *projectState {
+	l.mu.Lock()
+	p, ok := l.projects[projectID]
+	l.mu.Unlock()
+	if ok {
+		return p
+	}
+
+	month := Month(now)
+	limits := l.loadLimits(projectID)
+	usage, err := l.loadUsage(projectID, month)
+	if err != nil {
+		log.Printf("Failed to load usage for project %s: %v", projectID, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if p, ok := l.projects[projectID]; ok {
+		return p
+	}
+
+	p = &projectState{
+		limits:  limits,
+		bucket:  newBucket(limits.EventsPerSecond, limits.Burst, now),
+		stored:  usage,
+		pending: sharedModels.ProjectUsage{ProjectID: projectID, Month: month},
+		seen:    now,
+	}
+	l.projects[projectID] = p
+	return p
+}
+
+// rollMonth must be called with mu held. Usage from the previous month is
+// kept aside until it has been flushed.
+func (l *Limiter) rollMonth(p *projectState, now time.Time) {
+	month := Month(now)
+	if p.stored.Month == month {
+		return
+	}
+
+	if hasUsage(p.pending) {
+		l.carry = append(l.carry, p.pending)
+	}
+	p.stored = sharedModels.ProjectUsage{ProjectID: p.pending.ProjectID, Month: month}
+	p.pending = sharedModels.ProjectUsage{ProjectID: p.pending.ProjectID, Month: month}
+}
+
+// keyBucket must be called with mu held.
+func (l *Limiter) keyBucket(keyID string, limits Limits, now time.Time) *bucket {
+	if keyID == "" || limits.KeyEventsPerSecond <= 0 {
+		return nil
+	}
+
+	b, ok := l.keys[keyID]
+	if !ok {
+		b = newBucket(limits.KeyEventsPerSecond, limits.KeyBurst, now)
+		l.keys[keyID] = b
+	}
+	b.setLimits(limits.KeyEventsPerSecond, limits.KeyBurst)
+
+	return b
+}
+
+func (l *Limiter) loadLimits(projectID string) Limits {
+	limits := l.Defaults
+	if l.DB == nil {
+		return limits
+	}
+
+	var override sharedModels.ProjectLimit
+	err := l.DB.Where("project_id = ?", projectID).First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return limits
+	}
+	if err != nil {
+		log.Printf("Failed to load limits for project %s: %v", projectID, err)
+		return limits
+	}
+
+	if override.EventsPerSecond != nil {
+		limits.EventsPerSecond = *override.EventsPerSecond
+	}
+	if override.Burst != nil {
+		limits.Burst = *override.Burst
+	}
+	if override.KeyEventsPerSecond != nil {
+		limits.KeyEventsPerSecond = *override.KeyEventsPerSecond
+	}
+	if override.KeyBurst != nil {
+		limits.KeyBurst = *override.KeyBurst
+	}
+	if override.MonthlyQuota != nil {
+		limits.MonthlyQuota = *override.MonthlyQuota
+	}
+	if override.SpikeProtection != nil {
+		limits.SpikeProtection = *override.SpikeProtection
+	}
+
+	return limits
+}
+
+func (l *Limiter) loadUsage(projectID string, month string) (sharedModels.ProjectUsage, error) {
+	usage := sharedModels.ProjectUsage{ProjectID: projectID, Month: month}
+	if l.DB == nil {
+		return usage, nil
+	}
+
+	err := l.DB.Where("project_id = ? AND month = ?", projectID, month).First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return usage, nil
+	}
+
+	return usage, err
+}
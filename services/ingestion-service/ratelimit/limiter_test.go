@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	sharedModels "github.com/k1ngalph0x/atlas/shared/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testLimits = Limits{
+	EventsPerSecond:    10,
+	Burst:              20,
+	KeyEventsPerSecond: 5,
+	KeyBurst:           10,
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "limits.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&sharedModels.ProjectLimit{}, &sharedModels.ProjectUsage{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestAllow_TokenBuckets(t *testing.T) {
+	l := NewLimiter(nil, testLimits, 10)
+	now := time.Now()
+
+	if d := l.Allow("p1", "k1", 10, now); !d.Allowed {
+		t.Fatalf("expected burst within key limit to pass, got %+v", d)
+	}
+
+	d := l.Allow("p1", "k1", 1, now)
+	if d.Allowed || d.Reason != ReasonRateLimited {
+		t.Fatalf("expected key bucket to reject, got %+v", d)
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > time.Second {
+		t.Errorf("expected retry after one key token (200ms), got %v", d.RetryAfter)
+	}
+
+	if d := l.Allow("p1", "k2", 10, now); !d.Allowed {
+		t.Fatalf("expected second key to have its own bucket, got %+v", d)
+	}
+	if d := l.Allow("p1", "k3", 1, now); d.Allowed {
+		t.Fatal("expected project bucket to be empty after 20 events")
+	}
+
+	if d := l.Allow("p1", "k1", 5, now.Add(time.Second)); !d.Allowed {
+		t.Errorf("expected buckets to refill after a second, got %+v", d)
+	}
+
+	stats := l.Stats("p1", now.Add(time.Second))
+	if stats.Accepted != 25 || stats.Dropped[ReasonRateLimited] != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAllow_MonthlyQuota(t *testing.T) {
+	db := setupTestDB(t)
+	quota := int64(100)
+	db.Create(&sharedModels.ProjectLimit{ProjectID: "p1", MonthlyQuota: &quota})
+	db.Create(&sharedModels.ProjectUsage{ProjectID: "p1", Month: Month(time.Now()), Accepted: 95})
+
+	l := NewLimiter(db, Limits{}, 10)
+	now := time.Now()
+
+	if d := l.Allow("p1", "", 5, now); !d.Allowed {
+		t.Fatalf("expected events within quota to pass, got %+v", d)
+	}
+
+	d := l.Allow("p1", "", 1, now)
+	if d.Allowed || d.Reason != ReasonQuotaExceeded {
+		t.Fatalf("expected quota rejection, got %+v", d)
+	}
+	if d.RetryAfter != nextMonth(now).Sub(now) {
+		t.Errorf("expected retry at the start of next month, got %v", d.RetryAfter)
+	}
+
+	if err := l.Flush(now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	var usage sharedModels.ProjectUsage
+	db.Where("project_id = ? AND month = ?", "p1", Month(now)).First(&usage)
+	if usage.Accepted != 100 || usage.QuotaExceeded != 1 {
+		t.Errorf("expected flushed usage 100 accepted / 1 over quota, got %+v", usage)
+	}
+
+	if d := l.Allow("p2", "", 1000, now); !d.Allowed {
+		t.Errorf("expected project without limits to be unlimited, got %+v", d)
+	}
+}
+
+func TestAllow_SpikeProtection(t *testing.T) {
+	l := NewLimiter(nil, Limits{SpikeProtection: true}, 10)
+	l.random = func() float64 { return 0.9 }
+
+	start := time.Now().Truncate(time.Minute)
+	for i := 0; i < spikeWarmup; i++ {
+		minute := start.Add(time.Duration(i) * time.Minute)
+		if d := l.Allow("p1", "", 100, minute); !d.Allowed {
+			t.Fatalf("minute %d: expected baseline traffic to pass, got %+v", i, d)
+		}
+	}
+
+	spiking := start.Add(spikeWarmup * time.Minute)
+	if d := l.Allow("p1", "", 600, spiking); !d.Allowed {
+		t.Fatalf("expected traffic below 10x baseline to pass, got %+v", d)
+	}
+
+	d := l.Allow("p1", "", 900, spiking.Add(time.Second))
+	if d.Allowed || d.Reason != ReasonSpikeProtection {
+		t.Fatalf("expected spike to be sampled, got %+v", d)
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > time.Minute {
+		t.Errorf("expected retry within the current minute, got %v", d.RetryAfter)
+	}
+
+	stats := l.Stats("p1", spiking.Add(time.Second))
+	if !stats.Spike.Active || stats.Dropped[ReasonSpikeProtection] != 900 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestEvict_IdleBuckets(t *testing.T) {
+	l := NewLimiter(setupTestDB(t), testLimits, 10)
+	now := time.Now()
+
+	l.Allow("idle", "idle-key", 10, now)
+	l.Allow("busy", "busy-key", 1, now)
+	l.Allow("busy", "busy-key", 1, now.Add(idleAfter-time.Minute))
+
+	l.Evict(now.Add(idleAfter))
+	if _, ok := l.projects["idle"]; !ok {
+		t.Fatal("expected a project with unflushed usage to be kept")
+	}
+
+	if err := l.Flush(now.Add(idleAfter)); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	l.Evict(now.Add(idleAfter))
+	if _, ok := l.projects["idle"]; ok {
+		t.Error("expected the idle project to be evicted")
+	}
+	if _, ok := l.keys["idle-key"]; ok {
+		t.Error("expected the idle key to be evicted")
+	}
+	if _, ok := l.projects["busy"]; !ok {
+		t.Error("expected the busy project to be kept")
+	}
+	if _, ok := l.keys["busy-key"]; !ok {
+		t.Error("expected the busy key to be kept")
+	}
+
+	if d := l.Allow("idle", "idle-key", 10, now.Add(idleAfter)); !d.Allowed {
+		t.Errorf("expected an evicted project to start with full buckets, got %+v", d)
+	}
+}
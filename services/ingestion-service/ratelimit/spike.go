@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	spikeWindow = time.Minute
+
+	// spikeAlpha weights the newest minute in the baseline average.
+	spikeAlpha = 0.1
+
+	// spikeWarmup is how many minutes of traffic a project needs before its
+	// baseline is trusted.
+	spikeWarmup = 5
+
+	// spikeFloor keeps quiet projects from being sampled over a handful of
+	// events per minute.
+	spikeFloor = 600
+)
+
+// spike tracks a project's events per minute against an exponentially
+// weighted baseline of previous minutes.
+type spike struct {
+	windowStart time.Time
+	count       float64
+	baseline    float64
+	windows     int
+}
+
+func (s *spike) roll(now time.Time, multiplier float64) {
+	if s.windowStart.IsZero() {
+		s.windowStart = now.Truncate(spikeWindow)
+		return
+	}
+
+	for now.Sub(s.windowStart) >= spikeWindow {
+		// Spiking minutes are capped at the threshold so a flood cannot
+		// quickly raise the baseline it is measured against.
+		observed := math.Min(s.count, s.threshold(multiplier))
+		if s.windows == 0 {
+			s.baseline = observed
+		} else {
+			s.baseline = spikeAlpha*observed + (1-spikeAlpha)*s.baseline
+		}
+		s.windows++
+		s.count = 0
+		s.windowStart = s.windowStart.Add(spikeWindow)
+
+		// Skip empty minutes in one step after a long idle period.
+		if idle := int(now.Sub(s.windowStart) / spikeWindow); idle > 60 {
+			s.baseline *= math.Pow(1-spikeAlpha, float64(idle))
+			s.windows += idle
+			s.windowStart = s.windowStart.Add(time.Duration(idle) * spikeWindow)
+		}
+	}
+}
+
+func (s *spike) threshold(multiplier float64) float64 {
+	if s.windows < spikeWarmup {
+		return math.Inf(1)
+	}
+	return math.Max(s.baseline*multiplier, spikeFloor)
+}
+
+// keep records n offered events and returns the fraction that should be
+// kept, which is 1 unless the current minute is above the threshold.
+func (s *spike) keep(n int, now time.Time, multiplier float64) float64 {
+	s.roll(now, multiplier)
+	s.count += float64(n)
+
+	threshold := s.threshold(multiplier)
+	if s.count <= threshold {
+		return 1
+	}
+	return threshold / s.count
+}
+
+func (s *spike) remaining(now time.Time) time.Duration {
+	return s.windowStart.Add(spikeWindow).Sub(now)
+}
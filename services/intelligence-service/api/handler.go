@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/config"
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/models"
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/ollama"
+	"github.com/k1ngalph0x/atlas/shared/auth"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"gorm.io/gorm"
 )
@@ -31,18 +33,23 @@ func NewAIHandler(db *gorm.DB, config *config.Config, rabbitCh *amqp.Channel) *A
 	}
 }
 
-func(h *AIHandler) ProcessIssue(e models.IssueUpdateEvent){
+func(h *AIHandler) ProcessIssue(e models.IssueUpdateEvent) error {
 	log.Printf("ProcessIssue called for issue %s, count: %d, level: %s", e.IssueID, e.Count, e.Level)
+	if e.Status == "ignored"{
+		log.Printf("Issue %s is ignored, skipping", e.IssueID)
+		return nil
+	}
+
 	var existing models.IssueInsight
 	result := h.DB.Where("issue_id = ?", e.IssueID).First(&existing)
 	if result.Error == nil{
 		log.Printf("Issue %s already has insight", e.IssueID)
-		return
+		return nil
 	}
 
 	if e.Count < 5 && e.Level != "critical" && e.Level != "error"{
 		log.Printf("Low threshold (count: %d, level: %s)", e.Count, e.Level)
-		return
+		return nil
 	}
 
 	var issue struct{
@@ -57,8 +64,7 @@ func(h *AIHandler) ProcessIssue(e models.IssueUpdateEvent){
 	result = h.DB.Table("issues").Select("id, title, level, count, stack_trace").Where("id = ?", e.IssueID).Scan(&issue)
 
 	if result.Error != nil{
-		log.Printf("Failed to fetch issue details: %v", result.Error)
-		return
+		return fmt.Errorf("failed to fetch issue details: %w", result.Error)
 	}
 
 	queue := models.AIQueue{
@@ -72,8 +78,7 @@ func(h *AIHandler) ProcessIssue(e models.IssueUpdateEvent){
 
 	body, err := json.Marshal(queue)
 	if err != nil {
-		log.Printf("Failed to marshal job: %v", err)
-		return
+		return fmt.Errorf("failed to marshal job: %w", err)
 	}
 
 	err = h.RabbitCh.PublishWithContext(
@@ -90,12 +95,12 @@ func(h *AIHandler) ProcessIssue(e models.IssueUpdateEvent){
 	)
 
 	if err != nil {
-		log.Printf("Failed to publish job: %v", err)
-		return
+		return fmt.Errorf("failed to publish job: %w", err)
 	}
 
 
 	log.Printf("Published the job")
+	return nil
 
 } 
 
@@ -188,5 +193,15 @@ func (h *AIHandler) GetIssueInsight(c *gin.Context) {
 		return
 	}
 
+	ok, err := auth.CanAccessProject(h.DB, c.GetString("user_id"), insight.ProjectID)
+	if err != nil{
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify project access"})
+		return
+	}
+	if !ok{
+		c.JSON(http.StatusNotFound, gin.H{"error": "Insight not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"insight": insight})
 }
\ No newline at end of file
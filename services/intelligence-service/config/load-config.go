@@ -10,6 +10,8 @@ import (
 type Config struct {
 	DB PostgresConfig
 	TOKEN TokenConfig
+	CORS CorsConfig
+	ADMIN AdminConfig
 	KAFKA KafkaConfig
 	RABBITMQ RabbitConfig
 	OLLAMA OllamaConfig
@@ -31,8 +33,16 @@ type KafkaConfig struct{
 	Brokers []string
 }
 
+type AdminConfig struct{
+	UserIDs []string
+}
+
+type CorsConfig struct{
+	AllowedOrigins []string
+}
+
 type TokenConfig struct{
-	JwtKey string
+	JwksUrl string
 }
 
 type PostgresConfig struct {
@@ -61,7 +71,11 @@ func LoadConfig() (*Config, error) {
 		},
 
 		TOKEN: TokenConfig{
-			JwtKey: os.Getenv("JwtKey"),
+			JwksUrl: "http://localhost:8080/.well-known/jwks.json",
+		},
+
+		CORS: CorsConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
 		},
 
 		KAFKA: KafkaConfig{
@@ -80,6 +94,21 @@ func LoadConfig() (*Config, error) {
 		},
 	}
 
+	jwksUrl := os.Getenv("JWKS_URL")
+	if jwksUrl != ""{
+		config.TOKEN.JwksUrl = jwksUrl
+	}
+
+	admins := os.Getenv("ADMIN_USER_IDS")
+	if admins != ""{
+		config.ADMIN.UserIDs = strings.Split(admins, ",")
+	}
+
+	origins := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if origins != ""{
+		config.CORS.AllowedOrigins = strings.Split(origins, ",")
+	}
+
 	return config, nil
 
 }
\ No newline at end of file
@@ -1,38 +1,23 @@
 package kafka
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/api"
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/models"
+	"github.com/k1ngalph0x/atlas/shared/dlq"
 	"github.com/segmentio/kafka-go"
 )
 
-func Consume(handler *api.AIHandler) {
-	log.Println("Kafka consumer started, listening on topic: issue-updates")
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: handler.Config.KAFKA.Brokers,
-		Topic:   "issue-updates",
-		GroupID: "ai-consumers",
-	})
-	defer reader.Close()
-
-	for {
-		msg, err := reader.ReadMessage(context.Background())
-		if err != nil {
-			log.Println("Kafka read error:", err)
-			continue
-		}
-
+func NewConsumer(handler *api.AIHandler) *dlq.Consumer {
+	return dlq.NewConsumer(handler.Config.KAFKA.Brokers, "issue-updates", "ai-consumers", "issue-updates.ai-consumers.dlq", handler.DB, func(msg kafka.Message) error {
 		var event models.IssueUpdateEvent
-		err = json.Unmarshal(msg.Value, &event)
+		err := json.Unmarshal(msg.Value, &event)
 		if err != nil {
-			log.Println("Invalid event:", err)
-			continue
+			return dlq.Permanent(fmt.Errorf("invalid event: %w", err))
 		}
 
-		handler.ProcessIssue(event)
-	}
-}
\ No newline at end of file
+		return handler.ProcessIssue(event)
+	})
+}
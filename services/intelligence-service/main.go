@@ -10,6 +10,9 @@ import (
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/kafka"
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/models"
 	"github.com/k1ngalph0x/atlas/services/intelligence-service/rabbitmq"
+	"github.com/k1ngalph0x/atlas/shared/auth"
+	"github.com/k1ngalph0x/atlas/shared/dlq"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
 )
 
 func main() {
@@ -23,7 +26,7 @@ func main() {
 		log.Fatalf("DB error: %v", err)
 	}
 
-	if err := conn.AutoMigrate(&models.IssueInsight{}); err != nil {
+	if err := conn.AutoMigrate(&models.IssueInsight{}, &dlq.ReplayRecord{}); err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
@@ -39,25 +42,28 @@ func main() {
 
 	go handler.StartWorkers(3)
 
-	go kafka.Consume(handler)
+	consumer := kafka.NewConsumer(handler)
+	go consumer.Run()
 
 	router := gin.Default()
 
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
-	router.GET("/projects/:project_id/insights", handler.GetProjectInsights)
+	authMiddleware := auth.NewMiddleware(jwks.NewVerifier(config.TOKEN.JwksUrl), conn)
+
+	router.Use(auth.CORS(config.CORS.AllowedOrigins))
+	router.Use(authMiddleware.RequireAuth())
+	project := router.Group("/projects/:project_id", authMiddleware.RequireProjectAccess())
+	{
+		project.GET("/insights", handler.GetProjectInsights)
+	}
+
 	router.GET("/issues/:issue_id/insight", handler.GetIssueInsight)
+
+	admin := router.Group("/admin", auth.RequireAdmin(config.ADMIN.UserIDs))
+	{
+		admin.GET("/dlq", consumer.ListMessages)
+		admin.POST("/dlq/:offset/replay", consumer.ReplayMessage)
+	}
+
 	router.Run(":8083")
 
 }
\ No newline at end of file
@@ -20,12 +20,13 @@ type IssueInsight struct {
 }
 
 type IssueUpdateEvent struct {
-	IssueID   string    `json:"issue_id"`
-	ProjectID string    `json:"project_id"`
-	Count     int       `json:"count"`
-	Level     string    `json:"level"`
-	Status    string    `json:"status"`
-	UpdatedAt time.Time `json:"updated_at"`
+	IssueID        string    `json:"issue_id"`
+	ProjectID      string    `json:"project_id"`
+	Count          int       `json:"count"`
+	Level          string    `json:"level"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type AIQueue struct {
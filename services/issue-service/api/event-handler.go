@@ -0,0 +1,250 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultEventsLimit = 50
+	maxEventsLimit     = 100
+	maxRetentionDays   = 365
+)
+
+type ProjectSettingsRequest struct {
+	EventRetentionDays int `json:"event_retention_days" binding:"required,min=1"`
+}
+
+// storeEvent records the event in the same transaction as the issue upsert, so
+// an event is never counted without being stored.
+func storeEvent(tx *gorm.DB, issueID string, e models.Event) error {
+	timestamp := e.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+
+	record := models.EventRecord{
+		IssueID:    issueID,
+		ProjectID:  e.ProjectID,
+		Level:      e.Level,
+		Message:    e.Message,
+		StackTrace: e.StackTrace,
+		Tags:       e.Tags,
+		Release:    e.Release,
+		Timestamp:  timestamp,
+	}
+
+	return tx.Create(&record).Error
+}
+
+func (i *IssueHandler) GetIssueEvents(c *gin.Context) {
+	projectID := c.Param("project_id")
+	issueID := c.Param("issue_id")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultEventsLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+
+	query := i.DB.Model(&models.EventRecord{}).Where("issue_id = ? AND project_id = ?", issueID, projectID).Session(&gorm.Session{})
+
+	var total int64
+	result := query.Count(&total)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	var events []models.EventRecord
+	result = query.Order("timestamp desc, id desc").Offset((page - 1) * limit).Limit(limit).Find(&events)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"page":   page,
+		"limit":  limit,
+		"total":  total,
+	})
+}
+
+func (i *IssueHandler) GetLatestEvent(c *gin.Context) {
+	i.respondWithEdgeEvent(c, "timestamp desc, id desc")
+}
+
+func (i *IssueHandler) GetOldestEvent(c *gin.Context) {
+	i.respondWithEdgeEvent(c, "timestamp asc, id asc")
+}
+
+func (i *IssueHandler) respondWithEdgeEvent(c *gin.Context, order string) {
+	projectID := c.Param("project_id")
+	issueID := c.Param("issue_id")
+
+	var event models.EventRecord
+	result := i.DB.Where("issue_id = ? AND project_id = ?", issueID, projectID).Order(order).First(&event)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	i.respondWithEvent(c, event)
+}
+
+func (i *IssueHandler) GetIssueEvent(c *gin.Context) {
+	projectID := c.Param("project_id")
+	issueID := c.Param("issue_id")
+	eventID := c.Param("event_id")
+
+	var event models.EventRecord
+	result := i.DB.Where("id = ? AND issue_id = ? AND project_id = ?", eventID, issueID, projectID).First(&event)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	i.respondWithEvent(c, event)
+}
+
+func (i *IssueHandler) respondWithEvent(c *gin.Context, event models.EventRecord) {
+	var next, previous models.EventRecord
+	var nextID, previousID *string
+
+	result := i.DB.Select("id").
+		Where("issue_id = ? AND (timestamp > ? OR (timestamp = ? AND id > ?))", event.IssueID, event.Timestamp, event.Timestamp, event.ID).
+		Order("timestamp asc, id asc").First(&next)
+	if result.Error == nil {
+		nextID = &next.ID
+	}
+
+	result = i.DB.Select("id").
+		Where("issue_id = ? AND (timestamp < ? OR (timestamp = ? AND id < ?))", event.IssueID, event.Timestamp, event.Timestamp, event.ID).
+		Order("timestamp desc, id desc").First(&previous)
+	if result.Error == nil {
+		previousID = &previous.ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":             event,
+		"next_event_id":     nextID,
+		"previous_event_id": previousID,
+	})
+}
+
+func (i *IssueHandler) GetProjectSettings(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	settings, err := i.projectSettings(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (i *IssueHandler) UpdateProjectSettings(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	var req ProjectSettingsRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.EventRetentionDays > maxRetentionDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "event_retention_days cannot exceed 365"})
+		return
+	}
+
+	settings := models.ProjectSettings{
+		ProjectID:          projectID,
+		EventRetentionDays: req.EventRetentionDays,
+	}
+
+	result := i.DB.Save(&settings)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (i *IssueHandler) projectSettings(projectID string) (models.ProjectSettings, error) {
+	var settings models.ProjectSettings
+	result := i.DB.Where("project_id = ?", projectID).First(&settings)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.ProjectSettings{
+			ProjectID:          projectID,
+			EventRetentionDays: i.Config.EVENTS.RetentionDays,
+		}, nil
+	}
+
+	return settings, result.Error
+}
+
+func (h *IssueHandler) StartRetention(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.purgeExpiredEvents()
+		<-ticker.C
+	}
+}
+
+func (h *IssueHandler) purgeExpiredEvents() {
+	var settings []models.ProjectSettings
+	result := h.DB.Find(&settings)
+	if result.Error != nil {
+		log.Printf("Failed to fetch project settings: %v", result.Error)
+		return
+	}
+
+	custom := make([]string, 0, len(settings))
+	for _, s := range settings {
+		custom = append(custom, s.ProjectID)
+		cutoff := time.Now().AddDate(0, 0, -s.EventRetentionDays)
+
+		result := h.DB.Where("project_id = ? AND received_at < ?", s.ProjectID, cutoff).Delete(&models.EventRecord{})
+		if result.Error != nil {
+			log.Printf("Failed to purge events for project %s: %v", s.ProjectID, result.Error)
+		}
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -h.Config.EVENTS.RetentionDays)
+	query := h.DB.Where("received_at < ?", cutoff)
+	if len(custom) > 0 {
+		query = query.Where("project_id NOT IN ?", custom)
+	}
+
+	result = query.Delete(&models.EventRecord{})
+	if result.Error != nil {
+		log.Printf("Failed to purge expired events: %v", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Purged %d expired events", result.RowsAffected)
+	}
+}
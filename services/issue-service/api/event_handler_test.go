@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/k1ngalph0x/atlas/services/issue-service/config"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+)
+
+func setupEventRouter(h *IssueHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	project := r.Group("/projects/:project_id")
+	project.GET("/issues/:issue_id/events", h.GetIssueEvents)
+	project.GET("/issues/:issue_id/events/latest", h.GetLatestEvent)
+	project.GET("/issues/:issue_id/events/oldest", h.GetOldestEvent)
+	project.GET("/issues/:issue_id/events/:event_id", h.GetIssueEvent)
+	return r
+}
+
+func getJSON(t *testing.T, r *gin.Engine, path string, out any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+	}
+	return w.Code
+}
+
+type eventResponse struct {
+	Event           models.EventRecord `json:"event"`
+	NextEventID     *string            `json:"next_event_id"`
+	PreviousEventID *string            `json:"previous_event_id"`
+}
+
+// seedEvents stores n events for one issue, a minute apart, oldest first.
+func seedEvents(t *testing.T, h *IssueHandler, n int) (string, string, []models.EventRecord) {
+	t.Helper()
+	projectID := uuid.New().String()
+	issueID := uuid.New().String()
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	events := make([]models.EventRecord, n)
+	for i := range events {
+		events[i] = models.EventRecord{
+			ID:        uuid.New().String(),
+			IssueID:   issueID,
+			ProjectID: projectID,
+			Level:     "error",
+			Message:   "boom",
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+		}
+		if err := h.DB.Create(&events[i]).Error; err != nil {
+			t.Fatalf("failed to seed event: %v", err)
+		}
+	}
+	return projectID, issueID, events
+}
+
+func TestGetIssueEvents_Pagination(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	projectID, issueID, events := seedEvents(t, h, 5)
+	r := setupEventRouter(h)
+	path := "/projects/" + projectID + "/issues/" + issueID + "/events"
+
+	var page struct {
+		Events []models.EventRecord `json:"events"`
+		Page   int                  `json:"page"`
+		Limit  int                  `json:"limit"`
+		Total  int64                `json:"total"`
+	}
+	if code := getJSON(t, r, path+"?limit=2&page=2", &page); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if page.Total != 5 || page.Page != 2 || page.Limit != 2 || len(page.Events) != 2 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if page.Events[0].ID != events[2].ID || page.Events[1].ID != events[1].ID {
+		t.Errorf("expected the second page newest first, got %s, %s", page.Events[0].ID, page.Events[1].ID)
+	}
+
+	if code := getJSON(t, r, path+"?limit=2&page=3", &page); code != http.StatusOK || len(page.Events) != 1 || page.Events[0].ID != events[0].ID {
+		t.Errorf("expected the last page to hold the oldest event, got %d %+v", code, page.Events)
+	}
+	if code := getJSON(t, r, path+"?limit=500", &page); code != http.StatusOK || page.Limit != maxEventsLimit {
+		t.Errorf("expected limit to be capped at %d, got %d", maxEventsLimit, page.Limit)
+	}
+	if code := getJSON(t, r, path+"?page=0", &page); code != http.StatusBadRequest {
+		t.Errorf("page 0: expected 400, got %d", code)
+	}
+	if code := getJSON(t, r, "/projects/"+uuid.New().String()+"/issues/"+issueID+"/events", &page); code != http.StatusOK || page.Total != 0 {
+		t.Errorf("other project: expected no events, got %d total", page.Total)
+	}
+}
+
+func TestGetIssueEvent_Navigation(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	projectID, issueID, events := seedEvents(t, h, 3)
+	r := setupEventRouter(h)
+	base := "/projects/" + projectID + "/issues/" + issueID + "/events/"
+
+	var resp eventResponse
+	if code := getJSON(t, r, base+events[1].ID, &resp); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp.NextEventID == nil || *resp.NextEventID != events[2].ID || resp.PreviousEventID == nil || *resp.PreviousEventID != events[0].ID {
+		t.Errorf("expected neighbours %s and %s, got %v and %v", events[0].ID, events[2].ID, resp.PreviousEventID, resp.NextEventID)
+	}
+
+	resp = eventResponse{}
+	if code := getJSON(t, r, base+"latest", &resp); code != http.StatusOK || resp.Event.ID != events[2].ID || resp.NextEventID != nil {
+		t.Errorf("latest: expected %s with no next event, got %d %+v", events[2].ID, code, resp)
+	}
+
+	resp = eventResponse{}
+	if code := getJSON(t, r, base+"oldest", &resp); code != http.StatusOK || resp.Event.ID != events[0].ID || resp.PreviousEventID != nil {
+		t.Errorf("oldest: expected %s with no previous event, got %d %+v", events[0].ID, code, resp)
+	}
+
+	if code := getJSON(t, r, "/projects/"+uuid.New().String()+"/issues/"+issueID+"/events/"+events[1].ID, &resp); code != http.StatusNotFound {
+		t.Errorf("other project: expected 404, got %d", code)
+	}
+}
+
+func TestPurgeExpiredEvents(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t), Config: &config.Config{EVENTS: config.EventsConfig{RetentionDays: 30}}}
+
+	defaultProject := uuid.New().String()
+	customProject := uuid.New().String()
+	h.DB.Create(&models.ProjectSettings{ProjectID: customProject, EventRetentionDays: 7})
+
+	ages := map[string]int{"default-fresh": 10, "default-old": 40, "custom-fresh": 3, "custom-old": 10}
+	for name, days := range ages {
+		projectID := defaultProject
+		if strings.HasPrefix(name, "custom") {
+			projectID = customProject
+		}
+		record := models.EventRecord{ID: uuid.New().String(), IssueID: uuid.New().String(), ProjectID: projectID, Level: "error", Message: name, Timestamp: time.Now()}
+		h.DB.Create(&record)
+		h.DB.Model(&record).Update("received_at", time.Now().AddDate(0, 0, -days))
+	}
+
+	h.purgeExpiredEvents()
+
+	var kept []string
+	h.DB.Model(&models.EventRecord{}).Order("message").Pluck("message", &kept)
+	if len(kept) != 2 || kept[0] != "custom-fresh" || kept[1] != "default-fresh" {
+		t.Errorf("expected only events inside each project's retention to remain, got %v", kept)
+	}
+}
+
+func TestProcessEvents_StoresEventWithIssue(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	e := models.Event{ProjectID: uuid.New().String(), Level: "error", Message: "boom"}
+
+	if err := h.ProcessEvents(e); err != nil {
+		t.Fatalf("process events: %v", err)
+	}
+
+	h.DB.Migrator().DropTable(&models.EventRecord{})
+	if err := h.ProcessEvents(e); err == nil {
+		t.Fatal("expected an error when the event cannot be stored")
+	}
+
+	var issue models.Issue
+	h.DB.Where("project_id = ?", e.ProjectID).First(&issue)
+	if issue.Count != 1 {
+		t.Errorf("expected the failed event to leave the count at 1, got %d", issue.Count)
+	}
+}
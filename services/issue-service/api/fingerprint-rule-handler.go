@@ -0,0 +1,243 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/issue-service/fingerprint"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+)
+
+type FingerprintRuleRequest struct {
+	Name        string   `json:"name"        binding:"required"`
+	MatchField  string   `json:"match_field" binding:"required,oneof=message level stack_trace tag"`
+	MatchTag    string   `json:"match_tag"`
+	Pattern     string   `json:"pattern"`
+	Fingerprint []string `json:"fingerprint" binding:"required"`
+	Priority    int      `json:"priority"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// ruleCacheTTL bounds how long another instance can keep using rules that
+// were changed through a different one.
+const ruleCacheTTL = time.Minute
+
+type compiledRule struct {
+	models.FingerprintRule
+	re *regexp.Regexp
+}
+
+type projectRules struct {
+	rules    []compiledRule
+	loadedAt time.Time
+}
+
+func (r *FingerprintRuleRequest) validate() error {
+	if r.MatchField == "tag" && strings.TrimSpace(r.MatchTag) == "" {
+		return fmt.Errorf("match_tag is required when match_field is tag")
+	}
+
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+
+	_, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+
+	return fingerprint.ValidateComponents(r.Fingerprint)
+}
+
+func (i *IssueHandler) CreateFingerprintRule(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	var req FingerprintRuleRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	err = req.validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.FingerprintRule{
+		ProjectID:   projectID,
+		Name:        req.Name,
+		MatchField:  req.MatchField,
+		MatchTag:    req.MatchTag,
+		Pattern:     req.Pattern,
+		Fingerprint: req.Fingerprint,
+		Priority:    req.Priority,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	result := i.DB.Create(&rule)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create fingerprint rule"})
+		return
+	}
+	i.rules.Delete(projectID)
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+func (i *IssueHandler) GetFingerprintRules(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	var rules []models.FingerprintRule
+	result := i.DB.Where("project_id = ?", projectID).Order("priority desc, created_at asc").Find(&rules)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fingerprint rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+func (i *IssueHandler) UpdateFingerprintRule(c *gin.Context) {
+	projectID := c.Param("project_id")
+	ruleID := c.Param("rule_id")
+
+	var req FingerprintRuleRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	err = req.validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rule models.FingerprintRule
+	result := i.DB.Where("id = ? AND project_id = ?", ruleID, projectID).First(&rule)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return
+	}
+
+	rule.Name = req.Name
+	rule.MatchField = req.MatchField
+	rule.MatchTag = req.MatchTag
+	rule.Pattern = req.Pattern
+	rule.Fingerprint = req.Fingerprint
+	rule.Priority = req.Priority
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	result = i.DB.Save(&rule)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fingerprint rule"})
+		return
+	}
+	i.rules.Delete(projectID)
+
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+func (i *IssueHandler) DeleteFingerprintRule(c *gin.Context) {
+	projectID := c.Param("project_id")
+	ruleID := c.Param("rule_id")
+
+	result := i.DB.Where("id = ? AND project_id = ?", ruleID, projectID).Delete(&models.FingerprintRule{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete fingerprint rule"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return
+	}
+	i.rules.Delete(projectID)
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// resolveFingerprint picks the grouping key for an event: an explicit SDK
+// fingerprint wins, then the first matching project rule, then the default.
+func (h *IssueHandler) resolveFingerprint(e models.Event) string {
+	input := fingerprint.Input{
+		Message:    e.Message,
+		Level:      e.Level,
+		StackTrace: e.StackTrace,
+		Tags:       e.Tags,
+	}
+
+	if len(e.Fingerprint) > 0 {
+		return fingerprint.Custom(e.Fingerprint, input)
+	}
+
+	for _, rule := range h.projectRules(e.ProjectID) {
+		if matchRule(rule, e) {
+			return fingerprint.Custom(rule.Fingerprint, input)
+		}
+	}
+
+	return generateFingerprint(e.Message, &e.StackTrace)
+}
+
+// projectRules returns the project's active rules in match order with their
+// patterns compiled. They are cached per project until a rule changes or
+// ruleCacheTTL passes.
+func (h *IssueHandler) projectRules(projectID string) []compiledRule {
+	cached, ok := h.rules.Load(projectID)
+	if ok && time.Since(cached.(*projectRules).loadedAt) < ruleCacheTTL {
+		return cached.(*projectRules).rules
+	}
+
+	var rules []models.FingerprintRule
+	result := h.DB.Where("project_id = ? AND is_active = true", projectID).Order("priority desc, created_at asc").Find(&rules)
+	if result.Error != nil {
+		log.Printf("Failed to fetch fingerprint rules: %v", result.Error)
+		return nil
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			log.Printf("Invalid pattern on fingerprint rule %s: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, compiledRule{FingerprintRule: rule, re: re})
+	}
+
+	h.rules.Store(projectID, &projectRules{rules: compiled, loadedAt: time.Now()})
+	return compiled
+}
+
+func matchRule(rule compiledRule, e models.Event) bool {
+	var value string
+	switch rule.MatchField {
+	case "message":
+		value = e.Message
+	case "level":
+		value = e.Level
+	case "stack_trace":
+		value = e.StackTrace
+	case "tag":
+		v, ok := e.Tags[rule.MatchTag]
+		if !ok {
+			return false
+		}
+		value = v
+	default:
+		return false
+	}
+
+	return rule.re.MatchString(value)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/k1ngalph0x/atlas/services/issue-service/fingerprint"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+)
+
+func setupRuleRouter(h *IssueHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	project := r.Group("/projects/:project_id")
+	project.POST("/fingerprint-rules", h.CreateFingerprintRule)
+	project.PUT("/fingerprint-rules/:rule_id", h.UpdateFingerprintRule)
+	project.DELETE("/fingerprint-rules/:rule_id", h.DeleteFingerprintRule)
+	return r
+}
+
+func sendRule(t *testing.T, r *gin.Engine, method, path string, body any) (int, models.FingerprintRule) {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+
+	var resp struct {
+		Rule models.FingerprintRule `json:"rule"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp.Rule
+}
+
+func TestCreateFingerprintRule_RequiresPattern(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupRuleRouter(h)
+
+	code, _ := sendRule(t, r, http.MethodPost, "/projects/"+uuid.New().String()+"/fingerprint-rules", FingerprintRuleRequest{
+		Name:        "everything",
+		MatchField:  "message",
+		Fingerprint: []string{"all"},
+	})
+	if code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty pattern, got %d", code)
+	}
+}
+
+func TestResolveFingerprint_CachesRulesUntilChanged(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupRuleRouter(h)
+	projectID := uuid.New().String()
+	base := "/projects/" + projectID + "/fingerprint-rules"
+
+	e := models.Event{ProjectID: projectID, Level: "error", Message: "timeout talking to db-7"}
+	input := fingerprint.Input{Message: e.Message, Level: e.Level}
+	defaultFp := h.resolveFingerprint(e)
+
+	code, rule := sendRule(t, r, http.MethodPost, base, FingerprintRuleRequest{
+		Name:        "db timeouts",
+		MatchField:  "message",
+		Pattern:     "^timeout talking to db-",
+		Fingerprint: []string{"db-timeout"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if fp := h.resolveFingerprint(e); fp != fingerprint.Custom([]string{"db-timeout"}, input) {
+		t.Fatalf("expected the new rule to apply after create")
+	}
+
+	// A change made behind the handler's back is not seen until the cache is
+	// invalidated, which shows the rules are not reloaded per event.
+	h.DB.Model(&models.FingerprintRule{}).Where("id = ?", rule.ID).Update("is_active", false)
+	if fp := h.resolveFingerprint(e); fp != fingerprint.Custom([]string{"db-timeout"}, input) {
+		t.Fatalf("expected the cached rules to be used")
+	}
+
+	active := true
+	code, _ = sendRule(t, r, http.MethodPut, base+"/"+rule.ID, FingerprintRuleRequest{
+		Name:        "db timeouts",
+		MatchField:  "message",
+		Pattern:     "^timeout talking to db-",
+		Fingerprint: []string{"db"},
+		IsActive:    &active,
+	})
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if fp := h.resolveFingerprint(e); fp != fingerprint.Custom([]string{"db"}, input) {
+		t.Fatalf("expected the updated rule to apply after update")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, base+"/"+rule.ID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if fp := h.resolveFingerprint(e); fp != defaultFp {
+		t.Fatalf("expected the default fingerprint after delete")
+	}
+}
@@ -1,49 +1,68 @@
 package api
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/k1ngalph0x/atlas/services/issue-service/config"
+	"github.com/k1ngalph0x/atlas/services/issue-service/fingerprint"
 	"github.com/k1ngalph0x/atlas/services/issue-service/models"
-	publisher "github.com/k1ngalph0x/atlas/services/issue-service/utils"
 	"github.com/segmentio/kafka-go"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	topicIssueUpdates     = "issue-updates"
+	topicIssueResolved    = "issue-resolved"
+	topicIssueRegressions = "issue-regressions"
 )
 
 type IssueHandler struct{
 	DB *gorm.DB
 	Config *config.Config
 	Writer *kafka.Writer
+	ResolvedWriter *kafka.Writer
+	RegressionWriter *kafka.Writer
+	rules sync.Map
 }
 
-func NewIssueHandler(db *gorm.DB, config *config.Config, writer *kafka.Writer) *IssueHandler {
+func NewIssueHandler(db *gorm.DB, config *config.Config, writer *kafka.Writer, resolvedWriter *kafka.Writer, regressionWriter *kafka.Writer) *IssueHandler {
 	return &IssueHandler{
 		DB: db,
 		Config: config,
 		Writer: writer,
+		ResolvedWriter: resolvedWriter,
+		RegressionWriter: regressionWriter,
 	}
 }
 
 type IssueUpdateEvent struct {
-	IssueID   string    `json:"issue_id"`
-	ProjectID string    `json:"project_id"`
-	Count     int       `json:"count"`
-	Level     string    `json:"level"`
-	Status    string    `json:"status"`
-	UpdatedAt time.Time `json:"updated_at"`
+	IssueID        string    `json:"issue_id"`
+	ProjectID      string    `json:"project_id"`
+	Count          int       `json:"count"`
+	Level          string    `json:"level"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 
 func NewIssueResolvedWriter(config *config.Config) *kafka.Writer{
 	return &kafka.Writer{
 	Addr:     kafka.TCP(config.KAFKA.Brokers...),
-	Topic:    "issue-resolved",
+	Topic:    topicIssueResolved,
+	Balancer: &kafka.LeastBytes{},
+	}
+}
+
+func NewIssueRegressionWriter(config *config.Config) *kafka.Writer{
+	return &kafka.Writer{
+	Addr:     kafka.TCP(config.KAFKA.Brokers...),
+	Topic:    topicIssueRegressions,
 	Balancer: &kafka.LeastBytes{},
 	}
 }
@@ -51,84 +70,93 @@ func NewIssueResolvedWriter(config *config.Config) *kafka.Writer{
 func NewIssueUpdateWriter(config *config.Config) *kafka.Writer{
 	return &kafka.Writer{
 	Addr:     kafka.TCP(config.KAFKA.Brokers...),
-	Topic:    "issue-updates",
+	Topic:    topicIssueUpdates,
 	Balancer: &kafka.LeastBytes{},
 	}
 }
 
 func generateFingerprint(message string, stack *string) string {
-	raw := message
-	// if stack != nil{
-	// 	raw += "|" + *stack
-	// }
+	stackTrace := ""
+	if stack != nil{
+		stackTrace = *stack
+	}
 
-	hash := sha256.Sum256([]byte(raw))
-	return hex.EncodeToString(hash[:])
+	return fingerprint.Generate(message, stackTrace)
 }
 
 
-func(h *IssueHandler) ProcessEvents(e models.Event) {
-	var issue models.Issue
-	//var event models.Event
-	fp := generateFingerprint(e.Message, &e.StackTrace)
-
-	result := h.DB.Where("project_id = ? AND fingerprint = ?", e.ProjectID, fp).First(&issue)
-	if result.Error == nil{
-		err := h.DB.Model(&issue).Updates(map[string]interface{}{
-			"count":     gorm.Expr("count + ?", 1),
-			"last_seen": time.Now(),
-		}).Error
+func(h *IssueHandler) ProcessEvents(e models.Event) error {
+	fp := h.resolveFingerprint(e)
 
+	var issue models.Issue
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var created bool
+		var err error
+		issue, created, err = h.upsertIssue(tx, e, fp)
 		if err != nil{
-			log.Printf("Failed to update issue %s: %v", issue.ID, err)
-			return 
+			return err
 		}
 
-		h.DB.First(&issue, "id = ?", issue.ID)
-
-		updateEvent := IssueUpdateEvent{
-			IssueID:   issue.ID,
-			ProjectID: issue.ProjectID,
-			Count:     issue.Count,
-			Level:     issue.Level,
-			Status:    issue.Status,
-			UpdatedAt: time.Now(),
+		previous := ""
+		if !created{
+			previous, err = h.wakeSnoozed(tx, &issue)
+			if err != nil{
+				return err
+			}
+
+			if previous == ""{
+				previous, err = h.markRegressed(tx, &issue, e)
+				if err != nil{
+					return err
+				}
+			}
 		}
 
-		publisher.PublishEvent(h.Writer, issue.ProjectID, updateEvent)
+		err = storeEvent(tx, issue.ID, e)
+		if err != nil{
+			return err
+		}
 
-		return
-	}
+		return enqueueTransition(tx, issue, previous)
+	})
+	return err
+}
 
-	newIssue := models.Issue{
+// upsertIssue inserts a new issue or bumps the count of the existing one in a
+// single statement, so concurrent consumers never race on idx_project_fp.
+func(h *IssueHandler) upsertIssue(tx *gorm.DB, e models.Event, fp string) (models.Issue, bool, error) {
+	now := time.Now()
+	issue := models.Issue{
 		ID:          uuid.New().String(),
 		ProjectID:   e.ProjectID,
 		Fingerprint: fp,
+		FingerprintVersion: fingerprint.Version,
 		Title:       e.Message,
 		Level:       e.Level,
 		Count:       1,
 		StackTrace:  e.StackTrace,
-		FirstSeen:   time.Now(),
-		LastSeen:    time.Now(),
-		Status:      "open",
+		FirstSeen:   now,
+		LastSeen:    now,
+		Status:      models.StatusOpen,
 	}
 
-	err := h.DB.Create(&newIssue).Error
+	err := tx.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "project_id"}, {Name: "fingerprint"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count":      gorm.Expr("issues.count + 1"),
+				"last_seen":  now,
+				"updated_at": now,
+			}),
+		},
+		clause.Returning{},
+	).Create(&issue).Error
 	if err != nil{
-		log.Println("Failed to create issue:", err)
-		return
-	}
-
-	updateEvent := IssueUpdateEvent{
-		IssueID:   newIssue.ID,
-		ProjectID: newIssue.ProjectID,
-		Count:     newIssue.Count,
-		Level:     newIssue.Level,
-		Status:    newIssue.Status,
-		UpdatedAt: time.Now(),
+		return models.Issue{}, false, err
 	}
 
-	publisher.PublishEvent(h.Writer, newIssue.ProjectID, updateEvent)
+	// The conflict branch always leaves count >= 2, so 1 means we inserted.
+	return issue, issue.Count == 1, nil
 }
 
 func(i *IssueHandler) GetProjectIssue(c *gin.Context) {
@@ -166,6 +194,9 @@ func (i *IssueHandler) GetProjectOverview(c *gin.Context) {
 		TotalIssues    int64
 		OpenIssues     int64
 		ResolvedIssues int64
+		IgnoredIssues  int64
+		SnoozedIssues  int64
+		RegressedIssues int64
 		CriticalCount  int64
 		ErrorCount     int64
 	}
@@ -175,6 +206,9 @@ func (i *IssueHandler) GetProjectOverview(c *gin.Context) {
 			COUNT(*) as total_issues,
 			COUNT(*) FILTER (WHERE status = 'open') as open_issues,
 			COUNT(*) FILTER (WHERE status = 'resolved') as resolved_issues,
+			COUNT(*) FILTER (WHERE status = 'ignored') as ignored_issues,
+			COUNT(*) FILTER (WHERE status = 'snoozed') as snoozed_issues,
+			COUNT(*) FILTER (WHERE status = 'regressed') as regressed_issues,
 			COUNT(*) FILTER (WHERE level = 'critical') as critical_count,
 			COUNT(*) FILTER (WHERE level = 'error') as error_count
 		`).
@@ -190,6 +224,9 @@ func (i *IssueHandler) GetProjectOverview(c *gin.Context) {
 		"total_issues":    stats.TotalIssues,
 		"open_issues":     stats.OpenIssues,
 		"resolved_issues": stats.ResolvedIssues,
+		"ignored_issues":  stats.IgnoredIssues,
+		"snoozed_issues":  stats.SnoozedIssues,
+		"regressed_issues": stats.RegressedIssues,
 		"critical_count":  stats.CriticalCount,
 		"error_count":     stats.ErrorCount,
 	})
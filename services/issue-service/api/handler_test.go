@@ -0,0 +1,131 @@
+package api
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "issues.db") + "?_busy_timeout=10000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Issue{}, &models.FingerprintRule{}, &models.EventRecord{}, &models.OutboxMessage{}, &models.ProjectSettings{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestUpsertIssue_CreatesThenIncrements(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	e := models.Event{ProjectID: uuid.New().String(), Level: "error", Message: "boom"}
+
+	first, created, err := h.upsertIssue(h.DB, e, "fp-1")
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if !created || first.Count != 1 || first.Status != models.StatusOpen {
+		t.Fatalf("expected a new open issue with count 1, got created=%v %+v", created, first)
+	}
+
+	second, created, err := h.upsertIssue(h.DB, e, "fp-1")
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if created || second.ID != first.ID || second.Count != 2 {
+		t.Fatalf("expected existing issue %s with count 2, got created=%v %+v", first.ID, created, second)
+	}
+}
+
+func TestUpsertIssue_Concurrent(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	projectID := uuid.New().String()
+
+	const workers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ids := map[string]bool{}
+	createdCount := 0
+	counts := map[int]bool{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			issue, created, err := h.upsertIssue(h.DB, models.Event{ProjectID: projectID, Level: "error", Message: "boom"}, "fp-race")
+			if err != nil {
+				t.Errorf("upsert failed: %v", err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			ids[issue.ID] = true
+			counts[issue.Count] = true
+			if created {
+				createdCount++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(ids) != 1 {
+		t.Fatalf("expected every event to land on one issue, got %d", len(ids))
+	}
+	if createdCount != 1 {
+		t.Errorf("expected exactly one insert, got %d", createdCount)
+	}
+	if len(counts) != workers {
+		t.Errorf("expected each upsert to return a distinct count, got %d", len(counts))
+	}
+
+	var issue models.Issue
+	h.DB.Where("project_id = ? AND fingerprint = ?", projectID, "fp-race").First(&issue)
+	if issue.Count != workers {
+		t.Errorf("expected count %d, got %d", workers, issue.Count)
+	}
+}
+
+func TestUpsertIssue_ConcurrentFingerprints(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	projectID := uuid.New().String()
+	fingerprints := []string{"fp-a", "fp-b", "fp-c"}
+
+	const perFingerprint = 20
+	var wg sync.WaitGroup
+	for _, fp := range fingerprints {
+		for i := 0; i < perFingerprint; i++ {
+			wg.Add(1)
+			go func(fp string) {
+				defer wg.Done()
+				_, _, err := h.upsertIssue(h.DB, models.Event{ProjectID: projectID, Level: "error", Message: fp}, fp)
+				if err != nil {
+					t.Errorf("upsert failed: %v", err)
+				}
+			}(fp)
+		}
+	}
+	wg.Wait()
+
+	var issues []models.Issue
+	h.DB.Where("project_id = ?", projectID).Find(&issues)
+	if len(issues) != len(fingerprints) {
+		t.Fatalf("expected %d issues, got %d", len(fingerprints), len(issues))
+	}
+	for _, issue := range issues {
+		if issue.Count != perFingerprint {
+			t.Errorf("expected %s to have count %d, got %d", issue.Fingerprint, perFingerprint, issue.Count)
+		}
+	}
+}
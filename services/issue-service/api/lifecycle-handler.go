@@ -0,0 +1,279 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"gorm.io/gorm"
+)
+
+type IssueRegressionEvent struct {
+	IssueID     string     `json:"issue_id"`
+	ProjectID   string     `json:"project_id"`
+	Count       int        `json:"count"`
+	Level       string     `json:"level"`
+	Release     string     `json:"release,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+	RegressedAt time.Time  `json:"regressed_at"`
+}
+
+// allowedFrom lists the statuses each lifecycle endpoint may move an issue
+// out of. A resolved or ignored issue has to be reopened before it can be
+// snoozed, and a resolved one before it can be ignored.
+var allowedFrom = map[string][]string{
+	models.StatusResolved: {models.StatusOpen, models.StatusRegressed, models.StatusIgnored, models.StatusSnoozed},
+	models.StatusIgnored:  {models.StatusOpen, models.StatusRegressed, models.StatusSnoozed},
+	models.StatusSnoozed:  {models.StatusOpen, models.StatusRegressed, models.StatusSnoozed},
+	models.StatusOpen:     {models.StatusResolved, models.StatusIgnored, models.StatusSnoozed, models.StatusRegressed},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range allowedFrom[to] {
+		if status == from {
+			return true
+		}
+	}
+	return false
+}
+
+type SnoozeRequest struct {
+	Until       *time.Time `json:"until"`
+	Occurrences int        `json:"occurrences" binding:"omitempty,min=1"`
+}
+
+func (i *IssueHandler) ResolveIssue(c *gin.Context) {
+	now := time.Now()
+	i.transition(c, models.StatusResolved, map[string]interface{}{
+		"resolved_at": now,
+	})
+}
+
+func (i *IssueHandler) IgnoreIssue(c *gin.Context) {
+	i.transition(c, models.StatusIgnored, nil)
+}
+
+func (i *IssueHandler) ReopenIssue(c *gin.Context) {
+	i.transition(c, models.StatusOpen, nil)
+}
+
+func (i *IssueHandler) SnoozeIssue(c *gin.Context) {
+	var req SnoozeRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.Until == nil && req.Occurrences == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until or occurrences is required"})
+		return
+	}
+
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until must be in the future"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Until != nil {
+		updates["snooze_until"] = *req.Until
+	}
+	if req.Occurrences > 0 {
+		updates["snooze_until_count"] = gorm.Expr("count + ?", req.Occurrences)
+	}
+
+	i.transition(c, models.StatusSnoozed, updates)
+}
+
+// transition moves an issue to status, clearing any state that belonged to
+// the previous status, and publishes the change.
+func (i *IssueHandler) transition(c *gin.Context, status string, extra map[string]interface{}) {
+	projectID := c.Param("project_id")
+	issueID := c.Param("issue_id")
+
+	var issue models.Issue
+	result := i.DB.Where("id = ? AND project_id = ?", issueID, projectID).First(&issue)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+		return
+	}
+
+	previous := issue.Status
+	if previous == status && status != models.StatusSnoozed {
+		c.JSON(http.StatusOK, gin.H{"issue": issue})
+		return
+	}
+
+	if !canTransition(previous, status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot move a " + previous + " issue to " + status})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"status":             status,
+		"snooze_until":       nil,
+		"snooze_until_count": nil,
+	}
+	if status != models.StatusResolved {
+		updates["resolved_at"] = nil
+	}
+	for k, v := range extra {
+		updates[k] = v
+	}
+
+	err := i.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&issue).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.First(&issue, "id = ?", issue.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueTransition(tx, issue, previous)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"issue": issue})
+}
+
+// enqueueTransition queues the issue-updates message for an issue change, and
+// issue-resolved as well when the issue was resolved.
+func enqueueTransition(tx *gorm.DB, issue models.Issue, previous string) error {
+	updateEvent := IssueUpdateEvent{
+		IssueID:        issue.ID,
+		ProjectID:      issue.ProjectID,
+		Count:          issue.Count,
+		Level:          issue.Level,
+		Status:         issue.Status,
+		PreviousStatus: previous,
+		UpdatedAt:      time.Now(),
+	}
+
+	err := enqueue(tx, topicIssueUpdates, issue.ProjectID, updateEvent)
+	if err != nil {
+		return err
+	}
+
+	if issue.Status == models.StatusResolved {
+		return enqueue(tx, topicIssueResolved, issue.ProjectID, updateEvent)
+	}
+
+	return nil
+}
+
+func (h *IssueHandler) StartSnoozeExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.expireSnoozes()
+	}
+}
+
+// expireSnoozes reopens snoozed issues whose snooze_until has passed.
+func (h *IssueHandler) expireSnoozes() {
+	var issues []models.Issue
+	result := h.DB.Where("status = ? AND snooze_until <= ?", models.StatusSnoozed, time.Now()).Find(&issues)
+	if result.Error != nil {
+		log.Printf("Failed to fetch expired snoozes: %v", result.Error)
+		return
+	}
+
+	for _, issue := range issues {
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			previous, err := h.wakeSnoozed(tx, &issue)
+			if err != nil || previous == "" {
+				return err
+			}
+
+			return enqueueTransition(tx, issue, previous)
+		})
+		if err != nil {
+			log.Printf("Failed to unsnooze issue %s: %v", issue.ID, err)
+		}
+	}
+}
+
+func (h *IssueHandler) wakeSnoozed(tx *gorm.DB, issue *models.Issue) (string, error) {
+	if !snoozeExpired(*issue) {
+		return "", nil
+	}
+
+	err := tx.Model(issue).Updates(map[string]interface{}{
+		"status":             models.StatusOpen,
+		"snooze_until":       nil,
+		"snooze_until_count": nil,
+	}).Error
+	if err != nil {
+		return "", err
+	}
+
+	issue.Status = models.StatusOpen
+	issue.SnoozeUntil = nil
+	issue.SnoozeUntilCount = nil
+	return models.StatusSnoozed, nil
+}
+
+// markRegressed flips a resolved issue to regressed when a new event arrives
+// for it and returns the previous status, or "" when nothing changed.
+func (h *IssueHandler) markRegressed(tx *gorm.DB, issue *models.Issue, e models.Event) (string, error) {
+	if issue.Status != models.StatusResolved {
+		return "", nil
+	}
+
+	now := time.Now()
+	err := tx.Model(issue).Updates(map[string]interface{}{
+		"status":            models.StatusRegressed,
+		"last_regressed_at": now,
+		"regressed_release": e.Release,
+		"regression_count":  gorm.Expr("regression_count + ?", 1),
+	}).Error
+	if err != nil {
+		return "", err
+	}
+
+	regression := IssueRegressionEvent{
+		IssueID:     issue.ID,
+		ProjectID:   issue.ProjectID,
+		Count:       issue.Count,
+		Level:       issue.Level,
+		Release:     e.Release,
+		ResolvedAt:  issue.ResolvedAt,
+		RegressedAt: now,
+	}
+	err = enqueue(tx, topicIssueRegressions, issue.ProjectID, regression)
+	if err != nil {
+		return "", err
+	}
+
+	issue.Status = models.StatusRegressed
+	issue.LastRegressedAt = &now
+	issue.RegressedRelease = e.Release
+	issue.RegressionCount++
+	log.Printf("Issue %s regressed", issue.ID)
+
+	return models.StatusResolved, nil
+}
+
+// snoozeExpired reports whether a snoozed issue should wake up after its
+// count was just incremented.
+func snoozeExpired(issue models.Issue) bool {
+	if issue.Status != models.StatusSnoozed {
+		return false
+	}
+
+	if issue.SnoozeUntil != nil && !time.Now().Before(*issue.SnoozeUntil) {
+		return true
+	}
+
+	return issue.SnoozeUntilCount != nil && issue.Count >= *issue.SnoozeUntilCount
+}
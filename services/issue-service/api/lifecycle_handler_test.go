@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+)
+
+func setupLifecycleRouter(h *IssueHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	project := r.Group("/projects/:project_id")
+	project.POST("/issues/:issue_id/resolve", h.ResolveIssue)
+	project.POST("/issues/:issue_id/ignore", h.IgnoreIssue)
+	project.POST("/issues/:issue_id/snooze", h.SnoozeIssue)
+	project.POST("/issues/:issue_id/reopen", h.ReopenIssue)
+	return r
+}
+
+// newIssue creates an issue through ProcessEvents, as the consumer would.
+func newIssue(t *testing.T, h *IssueHandler) models.Issue {
+	t.Helper()
+	e := models.Event{ProjectID: uuid.New().String(), Level: "error", Message: "boom"}
+	if err := h.ProcessEvents(e); err != nil {
+		t.Fatalf("failed to create issue: %v", err)
+	}
+
+	var issue models.Issue
+	h.DB.Where("project_id = ?", e.ProjectID).First(&issue)
+	return issue
+}
+
+func recordEvent(t *testing.T, h *IssueHandler, issue models.Issue) models.Issue {
+	t.Helper()
+	if err := h.ProcessEvents(models.Event{ProjectID: issue.ProjectID, Level: "error", Message: "boom"}); err != nil {
+		t.Fatalf("failed to process event: %v", err)
+	}
+	return reload(h, issue)
+}
+
+func reload(h *IssueHandler, issue models.Issue) models.Issue {
+	var fresh models.Issue
+	h.DB.First(&fresh, "id = ?", issue.ID)
+	return fresh
+}
+
+func lifecycle(t *testing.T, r *gin.Engine, issue models.Issue, action string, body any) (int, models.Issue) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	w := httptest.NewRecorder()
+	path := "/projects/" + issue.ProjectID + "/issues/" + issue.ID + "/" + action
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, reader))
+
+	var resp struct {
+		Issue models.Issue `json:"issue"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp.Issue
+}
+
+// published reports whether the outbox holds a message on topic for the issue
+// with the given status and previous status.
+func published(t *testing.T, h *IssueHandler, topic string, issueID, status, previous string) bool {
+	t.Helper()
+	var messages []models.OutboxMessage
+	h.DB.Where("topic = ?", topic).Find(&messages)
+
+	for _, m := range messages {
+		var update IssueUpdateEvent
+		if err := json.Unmarshal([]byte(m.Payload), &update); err != nil {
+			t.Fatalf("invalid payload: %v", err)
+		}
+		if update.IssueID == issueID && update.Status == status && update.PreviousStatus == previous {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLifecycle_Transitions(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+	issue := newIssue(t, h)
+
+	steps := []struct {
+		action   string
+		status   string
+		previous string
+	}{
+		{"resolve", models.StatusResolved, models.StatusOpen},
+		{"reopen", models.StatusOpen, models.StatusResolved},
+		{"ignore", models.StatusIgnored, models.StatusOpen},
+		{"resolve", models.StatusResolved, models.StatusIgnored},
+		{"reopen", models.StatusOpen, models.StatusResolved},
+		{"ignore", models.StatusIgnored, models.StatusOpen},
+		{"reopen", models.StatusOpen, models.StatusIgnored},
+	}
+	for _, step := range steps {
+		code, got := lifecycle(t, r, issue, step.action, nil)
+		if code != http.StatusOK || got.Status != step.status {
+			t.Fatalf("%s: expected 200 and %s, got %d and %s", step.action, step.status, code, got.Status)
+		}
+		if !published(t, h, topicIssueUpdates, issue.ID, step.status, step.previous) {
+			t.Errorf("%s: expected an issue-updates message with previous_status %s", step.action, step.previous)
+		}
+	}
+
+	var resolved int64
+	h.DB.Model(&models.OutboxMessage{}).Where("topic = ?", topicIssueResolved).Count(&resolved)
+	if resolved != 2 {
+		t.Errorf("expected 2 issue-resolved messages, got %d", resolved)
+	}
+}
+
+func TestLifecycle_ResolveSetsAndReopenClearsResolvedAt(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+	issue := newIssue(t, h)
+
+	_, got := lifecycle(t, r, issue, "resolve", nil)
+	if got.ResolvedAt == nil {
+		t.Fatal("expected resolved_at to be set")
+	}
+
+	_, got = lifecycle(t, r, issue, "reopen", nil)
+	if got.ResolvedAt != nil {
+		t.Errorf("expected resolved_at to be cleared, got %v", got.ResolvedAt)
+	}
+}
+
+func TestLifecycle_RejectsInvalidTransitions(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+
+	resolved := newIssue(t, h)
+	lifecycle(t, r, resolved, "resolve", nil)
+	if code, _ := lifecycle(t, r, resolved, "ignore", nil); code != http.StatusConflict {
+		t.Errorf("expected 409 ignoring a resolved issue, got %d", code)
+	}
+	until := time.Now().Add(time.Hour)
+	if code, _ := lifecycle(t, r, resolved, "snooze", SnoozeRequest{Until: &until}); code != http.StatusConflict {
+		t.Errorf("expected 409 snoozing a resolved issue, got %d", code)
+	}
+
+	ignored := newIssue(t, h)
+	lifecycle(t, r, ignored, "ignore", nil)
+	if code, _ := lifecycle(t, r, ignored, "snooze", SnoozeRequest{Occurrences: 5}); code != http.StatusConflict {
+		t.Errorf("expected 409 snoozing an ignored issue, got %d", code)
+	}
+
+	if stored := reload(h, ignored); stored.Status != models.StatusIgnored {
+		t.Errorf("expected a rejected transition to leave the issue ignored, got %s", stored.Status)
+	}
+
+	open := newIssue(t, h)
+	if code, _ := lifecycle(t, r, open, "snooze", SnoozeRequest{}); code != http.StatusBadRequest {
+		t.Errorf("expected 400 without until or occurrences, got %d", code)
+	}
+	past := time.Now().Add(-time.Minute)
+	if code, _ := lifecycle(t, r, open, "snooze", SnoozeRequest{Until: &past}); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an until in the past, got %d", code)
+	}
+
+	missing := models.Issue{ID: uuid.New().String(), ProjectID: open.ProjectID}
+	if code, _ := lifecycle(t, r, missing, "resolve", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown issue, got %d", code)
+	}
+}
+
+func TestLifecycle_SnoozeUntilTime(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+	issue := newIssue(t, h)
+
+	until := time.Now().Add(time.Hour)
+	code, got := lifecycle(t, r, issue, "snooze", SnoozeRequest{Until: &until})
+	if code != http.StatusOK || got.Status != models.StatusSnoozed || got.SnoozeUntil == nil {
+		t.Fatalf("expected a snoozed issue with snooze_until, got %d %+v", code, got)
+	}
+
+	h.expireSnoozes()
+	got = reload(h, issue)
+	if got.Status != models.StatusSnoozed {
+		t.Fatalf("expected the issue to stay snoozed before until, got %s", got.Status)
+	}
+
+	h.DB.Model(&models.Issue{}).Where("id = ?", issue.ID).Update("snooze_until", time.Now().Add(-time.Second))
+	h.expireSnoozes()
+
+	got = reload(h, issue)
+	if got.Status != models.StatusOpen || got.SnoozeUntil != nil {
+		t.Fatalf("expected the issue to wake once until passed, got %+v", got)
+	}
+	if !published(t, h, topicIssueUpdates, issue.ID, models.StatusOpen, models.StatusSnoozed) {
+		t.Error("expected the wake-up to be published with previous_status snoozed")
+	}
+}
+
+func TestLifecycle_SnoozeForOccurrences(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+	issue := newIssue(t, h)
+
+	code, got := lifecycle(t, r, issue, "snooze", SnoozeRequest{Occurrences: 2})
+	if code != http.StatusOK || got.SnoozeUntilCount == nil || *got.SnoozeUntilCount != 3 {
+		t.Fatalf("expected snooze_until_count 3, got %d %+v", code, got)
+	}
+
+	got = recordEvent(t, h, issue)
+	if got.Status != models.StatusSnoozed {
+		t.Fatalf("expected the issue to stay snoozed after 1 of 2 occurrences, got %s", got.Status)
+	}
+
+	got = recordEvent(t, h, issue)
+	if got.Status != models.StatusOpen || got.SnoozeUntilCount != nil {
+		t.Fatalf("expected the issue to wake after 2 occurrences, got %+v", got)
+	}
+	if !published(t, h, topicIssueUpdates, issue.ID, models.StatusOpen, models.StatusSnoozed) {
+		t.Error("expected the wake-up to be published with previous_status snoozed")
+	}
+}
+
+func TestProcessEvents_ResolvedIssueRegresses(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+	issue := newIssue(t, h)
+	lifecycle(t, r, issue, "resolve", nil)
+
+	if err := h.ProcessEvents(models.Event{ProjectID: issue.ProjectID, Level: "error", Message: "boom", Release: "v2"}); err != nil {
+		t.Fatalf("failed to process event: %v", err)
+	}
+
+	got := reload(h, issue)
+	if got.Status != models.StatusRegressed || got.LastRegressedAt == nil || got.RegressedRelease != "v2" || got.RegressionCount != 1 {
+		t.Fatalf("expected a regressed issue with the release recorded, got %+v", got)
+	}
+	if !published(t, h, topicIssueUpdates, issue.ID, models.StatusRegressed, models.StatusResolved) {
+		t.Error("expected an issue-updates message with previous_status resolved")
+	}
+
+	var messages []models.OutboxMessage
+	h.DB.Where("topic = ?", topicIssueRegressions).Find(&messages)
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 regression message, got %d", len(messages))
+	}
+	var regression IssueRegressionEvent
+	json.Unmarshal([]byte(messages[0].Payload), &regression)
+	if regression.IssueID != issue.ID || regression.Release != "v2" || regression.ResolvedAt == nil {
+		t.Errorf("unexpected regression message: %+v", regression)
+	}
+
+	got = recordEvent(t, h, issue)
+	if got.RegressionCount != 1 {
+		t.Errorf("expected a regressed issue not to regress again, got regression_count %d", got.RegressionCount)
+	}
+}
+
+func TestProcessEvents_IgnoredAndSnoozedDoNotRegress(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	r := setupLifecycleRouter(h)
+
+	ignored := newIssue(t, h)
+	lifecycle(t, r, ignored, "ignore", nil)
+
+	snoozed := newIssue(t, h)
+	lifecycle(t, r, snoozed, "snooze", SnoozeRequest{Occurrences: 10})
+
+	if got := recordEvent(t, h, ignored); got.Status != models.StatusIgnored || got.RegressionCount != 0 {
+		t.Errorf("expected an ignored issue to stay ignored, got %+v", got)
+	}
+	if got := recordEvent(t, h, snoozed); got.Status != models.StatusSnoozed || got.RegressionCount != 0 {
+		t.Errorf("expected a snoozed issue to stay snoozed, got %+v", got)
+	}
+
+	var count int64
+	h.DB.Model(&models.OutboxMessage{}).Where("topic = ?", topicIssueRegressions).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no regression messages, got %d", count)
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxBatchSize    = 100
+	outboxWriteTimeout = 10 * time.Second
+	outboxMaxBackoff   = 5 * time.Minute
+	outboxRetention    = 24 * time.Hour
+)
+
+type outboxWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// enqueue stores a message in the outbox as part of tx; the relay publishes it
+// once the transaction has committed.
+func enqueue(tx *gorm.DB, topic string, key string, payload interface{}) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	message := models.OutboxMessage{
+		Topic:         topic,
+		Key:           key,
+		Payload:       string(bytes),
+		NextAttemptAt: time.Now(),
+	}
+
+	return tx.Create(&message).Error
+}
+
+func (h *IssueHandler) StartOutboxRelay(interval time.Duration) {
+	writers := map[string]outboxWriter{}
+	for _, w := range []*kafka.Writer{h.Writer, h.ResolvedWriter, h.RegressionWriter} {
+		if w != nil {
+			writers[w.Topic] = w
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			if h.relayOutbox(writers) < outboxBatchSize {
+				break
+			}
+		}
+
+		h.purgeSentOutbox()
+		<-ticker.C
+	}
+}
+
+// relayOutbox publishes pending messages oldest first and returns how many it
+// published. Every pass starts from the oldest unsent message, and it stops at
+// the first one that fails or is still backing off, so no message is published
+// ahead of an earlier one.
+func (h *IssueHandler) relayOutbox(writers map[string]outboxWriter) int {
+	var messages []models.OutboxMessage
+	result := h.DB.Where("sent_at IS NULL").
+		Order("created_at asc").Order("id asc").Limit(outboxBatchSize).Find(&messages)
+	if result.Error != nil {
+		log.Printf("Failed to fetch outbox messages: %v", result.Error)
+		return 0
+	}
+
+	now := time.Now()
+	for i, m := range messages {
+		if m.NextAttemptAt.After(now) {
+			return i
+		}
+
+		err := publishOutbox(writers, m)
+		if err != nil {
+			attempts := m.Attempts + 1
+			log.Printf("Failed to publish outbox message %s to %s (attempt %d): %v", m.ID, m.Topic, attempts, err)
+
+			h.DB.Model(&m).Updates(map[string]interface{}{
+				"attempts":        attempts,
+				"last_error":      err.Error(),
+				"next_attempt_at": time.Now().Add(outboxBackoff(attempts)),
+			})
+			return i
+		}
+
+		result := h.DB.Model(&m).Update("sent_at", time.Now())
+		if result.Error != nil {
+			log.Printf("Failed to mark outbox message %s as sent: %v", m.ID, result.Error)
+			return i
+		}
+	}
+
+	return len(messages)
+}
+
+func publishOutbox(writers map[string]outboxWriter, m models.OutboxMessage) error {
+	writer, ok := writers[m.Topic]
+	if !ok {
+		return fmt.Errorf("no writer for topic %s", m.Topic)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), outboxWriteTimeout)
+	defer cancel()
+
+	return writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(m.Key),
+		Value: []byte(m.Payload),
+	})
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	if attempts > 9 {
+		return outboxMaxBackoff
+	}
+
+	delay := time.Second << attempts
+	if delay > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+
+	return delay
+}
+
+func (h *IssueHandler) purgeSentOutbox() {
+	cutoff := time.Now().Add(-outboxRetention)
+	result := h.DB.Where("sent_at < ?", cutoff).Delete(&models.OutboxMessage{})
+	if result.Error != nil {
+		log.Printf("Failed to purge outbox: %v", result.Error)
+	}
+}
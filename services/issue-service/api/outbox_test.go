@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeWriter struct {
+	err      error
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func TestProcessEvents_EnqueuesUpdate(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	e := models.Event{ProjectID: uuid.New().String(), Level: "error", Message: "boom"}
+
+	h.ProcessEvents(e)
+	h.ProcessEvents(e)
+
+	var messages []models.OutboxMessage
+	h.DB.Order("created_at asc").Find(&messages)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 outbox messages, got %d", len(messages))
+	}
+
+	var update IssueUpdateEvent
+	if err := json.Unmarshal([]byte(messages[1].Payload), &update); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if messages[1].Topic != topicIssueUpdates || messages[1].Key != e.ProjectID || update.Count != 2 {
+		t.Errorf("unexpected outbox message: %+v", messages[1])
+	}
+}
+
+func TestProcessEvents_EnqueuesRegression(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	e := models.Event{ProjectID: uuid.New().String(), Level: "error", Message: "boom", Release: "v2"}
+
+	h.ProcessEvents(e)
+	h.DB.Model(&models.Issue{}).Where("project_id = ?", e.ProjectID).Update("status", models.StatusResolved)
+	h.ProcessEvents(e)
+
+	var count int64
+	h.DB.Model(&models.OutboxMessage{}).Where("topic = ?", topicIssueRegressions).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 regression message, got %d", count)
+	}
+}
+
+func TestRelayOutbox(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	if err := enqueue(h.DB, topicIssueUpdates, "project", IssueUpdateEvent{IssueID: "a"}); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+
+	failing := &fakeWriter{err: errors.New("broker unavailable")}
+	h.relayOutbox(map[string]outboxWriter{topicIssueUpdates: failing})
+
+	var m models.OutboxMessage
+	h.DB.First(&m)
+	if m.SentAt != nil || m.Attempts != 1 || m.LastError == "" || !m.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("expected message to be scheduled for retry, got %+v", m)
+	}
+
+	h.DB.Model(&m).Update("next_attempt_at", time.Now().Add(-time.Second))
+
+	writer := &fakeWriter{}
+	h.relayOutbox(map[string]outboxWriter{topicIssueUpdates: writer})
+
+	h.DB.First(&m)
+	if m.SentAt == nil || len(writer.messages) != 1 || string(writer.messages[0].Key) != "project" {
+		t.Fatalf("expected message to be published once and marked sent, got %+v", m)
+	}
+
+	if n := h.relayOutbox(map[string]outboxWriter{topicIssueUpdates: writer}); n != 0 || len(writer.messages) != 1 {
+		t.Errorf("expected sent messages not to be republished")
+	}
+}
+
+func TestRelayOutbox_HeadOfLine(t *testing.T) {
+	h := &IssueHandler{DB: setupTestDB(t)}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := enqueue(h.DB, topicIssueUpdates, "project", IssueUpdateEvent{IssueID: id}); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	failing := &fakeWriter{err: errors.New("broker unavailable")}
+	if n := h.relayOutbox(map[string]outboxWriter{topicIssueUpdates: failing}); n != 0 {
+		t.Fatalf("expected nothing published, got %d", n)
+	}
+
+	// The oldest message is backing off, so the newer ones must wait for it.
+	writer := &fakeWriter{}
+	if n := h.relayOutbox(map[string]outboxWriter{topicIssueUpdates: writer}); n != 0 || len(writer.messages) != 0 {
+		t.Fatalf("expected later messages to wait behind the failed one, published %d", len(writer.messages))
+	}
+
+	h.DB.Model(&models.OutboxMessage{}).Where("attempts > 0").Update("next_attempt_at", time.Now().Add(-time.Second))
+
+	if n := h.relayOutbox(map[string]outboxWriter{topicIssueUpdates: writer}); n != 3 {
+		t.Fatalf("expected all 3 messages published, got %d", n)
+	}
+
+	var order []string
+	for _, msg := range writer.messages {
+		var update IssueUpdateEvent
+		json.Unmarshal(msg.Value, &update)
+		order = append(order, update.IssueID)
+	}
+	if len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Errorf("expected messages in enqueue order, got %v", order)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
@@ -10,15 +11,30 @@ import (
 type Config struct {
 	DB PostgresConfig
 	TOKEN TokenConfig
+	CORS CorsConfig
+	ADMIN AdminConfig
 	KAFKA KafkaConfig
+	EVENTS EventsConfig
+}
+
+type EventsConfig struct{
+	RetentionDays int
 }
 
 type KafkaConfig struct{
 	Brokers []string
 }
 
+type AdminConfig struct{
+	UserIDs []string
+}
+
+type CorsConfig struct{
+	AllowedOrigins []string
+}
+
 type TokenConfig struct{
-	JwtKey string
+	JwksUrl string
 }
 
 type PostgresConfig struct {
@@ -47,12 +63,40 @@ func LoadConfig() (*Config, error) {
 		},
 
 		TOKEN: TokenConfig{
-			JwtKey: os.Getenv("JwtKey"),
+			JwksUrl: "http://localhost:8080/.well-known/jwks.json",
+		},
+
+		CORS: CorsConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
 		},
 
 		KAFKA: KafkaConfig{
 			Brokers: strings.Split(os.Getenv("KAFKA_BROKERS"), ","),
 		},
+
+		EVENTS: EventsConfig{
+			RetentionDays: 30,
+		},
+	}
+
+	retention, err := strconv.Atoi(os.Getenv("EVENT_RETENTION_DAYS"))
+	if err == nil && retention > 0{
+		config.EVENTS.RetentionDays = retention
+	}
+
+	jwksUrl := os.Getenv("JWKS_URL")
+	if jwksUrl != ""{
+		config.TOKEN.JwksUrl = jwksUrl
+	}
+
+	admins := os.Getenv("ADMIN_USER_IDS")
+	if admins != ""{
+		config.ADMIN.UserIDs = strings.Split(admins, ",")
+	}
+
+	origins := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if origins != ""{
+		config.CORS.AllowedOrigins = strings.Split(origins, ",")
 	}
 
 	return config, nil
@@ -0,0 +1,81 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Input struct {
+	Message    string
+	Level      string
+	StackTrace string
+	Tags       map[string]string
+}
+
+var variablePattern = regexp.MustCompile(`^\{\{\s*([\w.-]+)\s*\}\}$`)
+
+// Custom hashes an explicit fingerprint. Components are literal strings or
+// variables: {{ default }}, {{ message }}, {{ level }} and {{ tags.<key> }}.
+func Custom(components []string, in Input) string {
+	parts := make([]string, len(components))
+	for i, component := range components {
+		parts[i] = expand(component, in)
+	}
+
+	hash := sha256.Sum256([]byte("custom\n" + strings.Join(parts, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+func ValidateComponents(components []string) error {
+	if len(components) == 0 {
+		return fmt.Errorf("fingerprint must have at least one component")
+	}
+
+	for _, component := range components {
+		if strings.TrimSpace(component) == "" {
+			return fmt.Errorf("fingerprint components cannot be empty")
+		}
+
+		m := variablePattern.FindStringSubmatch(component)
+		if m == nil {
+			if strings.Contains(component, "{{") {
+				return fmt.Errorf("malformed variable %q", component)
+			}
+			continue
+		}
+
+		name := m[1]
+		switch {
+		case name == "default", name == "message", name == "level":
+		case strings.HasPrefix(name, "tags.") && len(name) > len("tags."):
+		default:
+			return fmt.Errorf("unknown variable %q", component)
+		}
+	}
+
+	return nil
+}
+
+func expand(component string, in Input) string {
+	m := variablePattern.FindStringSubmatch(component)
+	if m == nil {
+		return component
+	}
+
+	name := m[1]
+	switch {
+	case name == "default":
+		return Generate(in.Message, in.StackTrace)
+	case name == "message":
+		return NormalizeMessage(in.Message)
+	case name == "level":
+		return in.Level
+	case strings.HasPrefix(name, "tags."):
+		return in.Tags[strings.TrimPrefix(name, "tags.")]
+	}
+
+	return component
+}
@@ -0,0 +1,69 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Version identifies the grouping algorithm. Bump it whenever the output of
+// Generate changes so issues created by older versions can be told apart.
+// Version 1 hashed the raw message only.
+const Version = 2
+
+const maxFrames = 10
+
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	InApp    bool   `json:"in_app"`
+}
+
+var (
+	quotedPattern = regexp.MustCompile(`(^|[^\w])("[^"]*"|'[^']*'|` + "`[^`]*`)")
+	uuidPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexPattern    = regexp.MustCompile(`(?i)\b(0x[0-9a-f]+|[0-9a-f]{8,})\b`)
+	numberPattern = regexp.MustCompile(`\b\d+(\.\d+)?`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+func Generate(message, stackTrace string) string {
+	parts := []string{NormalizeMessage(message)}
+	for _, frame := range InAppFrames(ParseStack(stackTrace)) {
+		parts = append(parts, frame.Function+"@"+path.Base(frame.File))
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+func NormalizeMessage(message string) string {
+	msg := quotedPattern.ReplaceAllString(message, "${1}<str>")
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = hexPattern.ReplaceAllStringFunc(msg, func(s string) string {
+		if !strings.ContainsAny(s, "0123456789") {
+			return s
+		}
+		return "<hex>"
+	})
+	msg = numberPattern.ReplaceAllString(msg, "<num>")
+	msg = spacePattern.ReplaceAllString(msg, " ")
+	return strings.TrimSpace(msg)
+}
+
+func InAppFrames(frames []Frame) []Frame {
+	var inApp []Frame
+	for _, frame := range frames {
+		if !frame.InApp {
+			continue
+		}
+		inApp = append(inApp, frame)
+		if len(inApp) == maxFrames {
+			break
+		}
+	}
+	return inApp
+}
@@ -0,0 +1,169 @@
+package fingerprint_test
+
+import (
+	"testing"
+
+	"github.com/k1ngalph0x/atlas/services/issue-service/fingerprint"
+)
+
+const goStack = `goroutine 42 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+github.com/k1ngalph0x/atlas-go-sdk.(*Client).CaptureError(0xc0001a2000, {0x1029c40, 0xc000012345})
+	/home/dev/go/pkg/mod/github.com/k1ngalph0x/atlas-go-sdk/atlas.go:120 +0x6b
+main.main.func2(0xc0000f8000)
+	/home/dev/app/main.go:31 +0x7a
+github.com/gin-gonic/gin.(*Context).Next(...)
+	/home/dev/go/pkg/mod/github.com/gin-gonic/gin@v1.11.0/context.go:192
+created by net/http.(*Server).Serve in goroutine 1
+	/usr/local/go/src/net/http/server.go:3454 +0x485
+`
+
+func TestParseStack_Go(t *testing.T) {
+	frames := fingerprint.ParseStack(goStack)
+	if len(frames) != 5 {
+		t.Fatalf("expected 5 frames, got %d: %+v", len(frames), frames)
+	}
+
+	inApp := fingerprint.InAppFrames(frames)
+	if len(inApp) != 1 {
+		t.Fatalf("expected 1 in-app frame, got %+v", inApp)
+	}
+	if inApp[0].Function != "main.main.func2" || inApp[0].File != "/home/dev/app/main.go" || inApp[0].Line != 31 {
+		t.Errorf("unexpected in-app frame: %+v", inApp[0])
+	}
+}
+
+func TestParseStack_Python(t *testing.T) {
+	stack := `Traceback (most recent call last):
+  File "/app/server.py", line 10, in handle
+    process()
+  File "/app/worker.py", line 22, in process
+    raise ValueError("bad")
+  File "/usr/lib/python3.11/json/__init__.py", line 346, in loads
+ValueError: bad`
+
+	frames := fingerprint.ParseStack(stack)
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %+v", frames)
+	}
+	if frames[0].InApp || frames[1].Function != "process" || frames[2].Function != "handle" {
+		t.Errorf("expected innermost frame first, got %+v", frames)
+	}
+}
+
+func TestParseStack_JavaScriptAndJava(t *testing.T) {
+	js := fingerprint.ParseStack(`TypeError: x is undefined
+    at handler (/srv/app/routes.js:14:9)
+    at /srv/app/node_modules/express/lib/router.js:95:5`)
+	if len(js) != 2 || !js[0].InApp || js[1].InApp {
+		t.Errorf("unexpected js frames: %+v", js)
+	}
+
+	java := fingerprint.ParseStack(`java.lang.NullPointerException
+	at com.example.UserService.load(UserService.java:42)
+	at java.base/java.lang.Thread.run(Thread.java:833)`)
+	if len(java) != 2 || java[0].Function != "com.example.UserService.load" || java[0].Line != 42 || java[1].InApp {
+		t.Errorf("unexpected java frames: %+v", java)
+	}
+}
+
+func TestNormalizeMessage(t *testing.T) {
+	cases := map[string]string{
+		"DatabaseTimeoutError: timeout after 30s while connecting to user-db": "DatabaseTimeoutError: timeout after <num>s while connecting to user-db",
+		"cannot read property 'email' of null":                                "cannot read property <str> of null",
+		`user "bob" not found`:                                                "user <str> not found",
+		"order 3fa85f64-5717-4562-b3fc-2c963f66afa6 missing":                  "order <uuid> missing",
+		"bad pointer 0xc000012345 at offset 17":                               "bad pointer <hex> at offset <num>",
+		"checksum 9f86d081884c7d65 mismatch":                                  "checksum <hex> mismatch",
+		"can't connect to ipv4 host":                                          "can't connect to ipv4 host",
+	}
+
+	for in, want := range cases {
+		got := fingerprint.NormalizeMessage(in)
+		if got != want {
+			t.Errorf("NormalizeMessage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerate_GroupsVariableMessages(t *testing.T) {
+	a := fingerprint.Generate("timeout after 30s", goStack)
+	b := fingerprint.Generate("timeout after 31s", goStack)
+	if a != b {
+		t.Error("expected messages differing only in numbers to share a fingerprint")
+	}
+}
+
+func TestGenerate_IgnoresLineNumbersAndAddresses(t *testing.T) {
+	moved := `goroutine 7 [running]:
+main.main.func2(0xc000999999)
+	/home/dev/app/main.go:58 +0x11
+`
+	if fingerprint.Generate("boom", goStack) != fingerprint.Generate("boom", moved) {
+		t.Error("expected line numbers, addresses and goroutine ids to be ignored")
+	}
+}
+
+func TestGenerate_SplitsByCallSite(t *testing.T) {
+	other := `goroutine 1 [running]:
+main.loadUser(0x1)
+	/home/dev/app/users.go:12 +0x11
+`
+	if fingerprint.Generate("boom", goStack) == fingerprint.Generate("boom", other) {
+		t.Error("expected same message from different in-app frames to split")
+	}
+}
+
+func TestCustom_Variables(t *testing.T) {
+	in := fingerprint.Input{Message: "boom", StackTrace: goStack, Tags: map[string]string{"service": "billing"}}
+	other := fingerprint.Input{Message: "boom", StackTrace: goStack, Tags: map[string]string{"service": "auth"}}
+
+	split := []string{"{{ default }}", "{{ tags.service }}"}
+	if fingerprint.Custom(split, in) == fingerprint.Custom(split, other) {
+		t.Error("expected tags.service to split fingerprints")
+	}
+
+	static := []string{"database-timeout"}
+	if fingerprint.Custom(static, in) != fingerprint.Custom(static, other) {
+		t.Error("expected literal fingerprint to group events")
+	}
+	if fingerprint.Custom(static, in) == fingerprint.Generate(in.Message, in.StackTrace) {
+		t.Error("expected custom fingerprint not to collide with default")
+	}
+}
+
+func TestValidateComponents(t *testing.T) {
+	valid := [][]string{{"literal"}, {"{{ default }}", "{{tags.service}}"}, {"{{ message }}", "{{ level }}"}}
+	for _, c := range valid {
+		if err := fingerprint.ValidateComponents(c); err != nil {
+			t.Errorf("expected %v to be valid, got %v", c, err)
+		}
+	}
+
+	invalid := [][]string{nil, {""}, {"{{ unknown }}"}, {"{{ tags. }}"}, {"{{ default"}}
+	for _, c := range invalid {
+		if err := fingerprint.ValidateComponents(c); err == nil {
+			t.Errorf("expected %v to be rejected", c)
+		}
+	}
+}
+
+func TestParseStack_GoPathAppFrames(t *testing.T) {
+	stack := `goroutine 1 [running]:
+main.handler(0xc0000f8000)
+	/go/src/app/main.go:18 +0x7a
+app/store.(*Store).Get(...)
+	/go/src/app/store/store.go:42
+encoding/json.Unmarshal({0xc000120000, 0x10, 0x10}, {0x5f2a20, 0xc000010018})
+	/usr/lib/go/src/encoding/json/decode.go:108 +0x1e5
+`
+
+	inApp := fingerprint.InAppFrames(fingerprint.ParseStack(stack))
+	if len(inApp) != 2 {
+		t.Fatalf("expected 2 in-app frames, got %+v", inApp)
+	}
+	if inApp[0].File != "/go/src/app/main.go" || inApp[1].File != "/go/src/app/store/store.go" {
+		t.Errorf("expected the /go/src/app frames to be in-app, got %+v", inApp)
+	}
+}
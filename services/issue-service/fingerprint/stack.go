@@ -0,0 +1,161 @@
+package fingerprint
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	goFilePattern      = regexp.MustCompile(`^\s+(.+?):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	goroutinePattern   = regexp.MustCompile(`^goroutine \d+ \[.*\]:$`)
+	goroutineIDPattern = regexp.MustCompile(` in goroutine \d+$`)
+	pythonPattern      = regexp.MustCompile(`^\s*File "(.+?)", line (\d+), in (.+)$`)
+	jsNamedPattern     = regexp.MustCompile(`^\s*at (?:async )?(.+?) \((.+?):(\d+)(?::\d+)?\)$`)
+	jsAnonPattern      = regexp.MustCompile(`^\s*at (?:async )?(.+?):(\d+)(?::\d+)?$`)
+	javaPattern        = regexp.MustCompile(`^\s*at ([\w$.<>/]+)\(([^:)]+)(?::(\d+))?\)$`)
+)
+
+var vendorPrefixes = []string{
+	"runtime.",
+	"runtime/",
+	"github.com/gin-gonic/",
+	"github.com/k1ngalph0x/atlas-go-sdk",
+	"net/http.",
+	"testing.",
+	"reflect.",
+	"sync.",
+	"database/sql.",
+	"internal/",
+	"node:",
+	"java.",
+	"javax.",
+	"sun.",
+	"jdk.",
+}
+
+// GOROOT locations of the standard library. "/go/src/" alone would also
+// match application code built under GOPATH, such as /go/src/app/main.go.
+var vendorPaths = []string{
+	"/usr/local/go/src/",
+	"/usr/lib/go/src/",
+	"/usr/lib/golang/src/",
+	"/Program Files/Go/src/",
+	"/Cellar/go/",
+	"/go/pkg/mod/",
+	"/vendor/",
+	"/node_modules/",
+	"/site-packages/",
+	"/dist-packages/",
+	"/lib/python",
+	"<frozen ",
+}
+
+// ParseStack understands Go panics and debug.Stack() output as well as Python,
+// JavaScript and Java traces. Lines it does not recognise are skipped.
+func ParseStack(stack string) []Frame {
+	lines := strings.Split(strings.ReplaceAll(stack, "\r\n", "\n"), "\n")
+
+	var frames []Frame
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		if line == "" || goroutinePattern.MatchString(line) {
+			continue
+		}
+
+		if m := pythonPattern.FindStringSubmatch(line); m != nil {
+			frames = append(frames, newFrame(m[3], m[1], m[2]))
+			continue
+		}
+
+		if m := javaPattern.FindStringSubmatch(line); m != nil {
+			frames = append(frames, newFrame(m[1], m[2], m[3]))
+			continue
+		}
+
+		if m := jsNamedPattern.FindStringSubmatch(line); m != nil {
+			frames = append(frames, newFrame(m[1], m[2], m[3]))
+			continue
+		}
+
+		if m := jsAnonPattern.FindStringSubmatch(line); m != nil {
+			frames = append(frames, newFrame("", m[1], m[2]))
+			continue
+		}
+
+		if i+1 < len(lines) {
+			fileMatch := goFilePattern.FindStringSubmatch(lines[i+1])
+			if fileMatch != nil {
+				frames = append(frames, newFrame(goFunction(line), fileMatch[1], fileMatch[2]))
+				i++
+			}
+		}
+	}
+
+	// Python lists the innermost call last; everything else lists it first.
+	if strings.Contains(stack, "Traceback (most recent call last)") {
+		for l, r := 0, len(frames)-1; l < r; l, r = l+1, r-1 {
+			frames[l], frames[r] = frames[r], frames[l]
+		}
+	}
+
+	return frames
+}
+
+func newFrame(function, file, line string) Frame {
+	n, _ := strconv.Atoi(line)
+	frame := Frame{
+		Function: strings.TrimSpace(function),
+		File:     strings.TrimSpace(file),
+		Line:     n,
+	}
+	frame.InApp = isInApp(frame)
+	return frame
+}
+
+// goFunction strips call arguments, goroutine ids and the "created by" prefix
+// from a Go frame header such as "main.handler(0xc000010000, {0x1, 0x2})".
+func goFunction(line string) string {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "created by ")
+	line = goroutineIDPattern.ReplaceAllString(line, "")
+
+	if !strings.HasSuffix(line, ")") {
+		return line
+	}
+
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+func isInApp(frame Frame) bool {
+	if frame.Function == "panic" {
+		return false
+	}
+
+	for _, prefix := range vendorPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return false
+		}
+	}
+
+	file := strings.ReplaceAll(frame.File, "\\", "/")
+	for _, p := range vendorPaths {
+		if strings.Contains(file, p) {
+			return false
+		}
+	}
+
+	return frame.Function != "" || frame.File != ""
+}
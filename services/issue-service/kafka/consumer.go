@@ -1,41 +1,23 @@
 package kafka
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 
 	"github.com/k1ngalph0x/atlas/services/issue-service/api"
 	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"github.com/k1ngalph0x/atlas/shared/dlq"
 	"github.com/segmentio/kafka-go"
 )
 
-
-func Consume(handler *api.IssueHandler){
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: handler.Config.KAFKA.Brokers,
-		Topic: "atlas-events",
-		GroupID: "issue-consumers",
-	})
-
-	defer reader.Close()
-
-	for {
-		msg, err := reader.ReadMessage(context.Background())
-		if err != nil{
-			log.Println("Kafka read error:", err)
-			continue
-		}
-
+func NewConsumer(handler *api.IssueHandler) *dlq.Consumer {
+	return dlq.NewConsumer(handler.Config.KAFKA.Brokers, "atlas-events", "issue-consumers", "atlas-events.dlq", handler.DB, func(msg kafka.Message) error {
 		var event models.Event
-		err = json.Unmarshal(msg.Value, &event)
-		if err != nil{
-			log.Println("Invalid event:", err)
-			continue
+		err := json.Unmarshal(msg.Value, &event)
+		if err != nil {
+			return dlq.Permanent(fmt.Errorf("invalid event: %w", err))
 		}
 
-		handler.ProcessEvents(event)
-		
-	}
+		return handler.ProcessEvents(event)
+	})
 }
-
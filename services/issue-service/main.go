@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/k1ngalph0x/atlas/services/issue-service/api"
@@ -9,6 +10,9 @@ import (
 	"github.com/k1ngalph0x/atlas/services/issue-service/db"
 	"github.com/k1ngalph0x/atlas/services/issue-service/kafka"
 	"github.com/k1ngalph0x/atlas/services/issue-service/models"
+	"github.com/k1ngalph0x/atlas/shared/auth"
+	"github.com/k1ngalph0x/atlas/shared/dlq"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
 )
 
 func main() {
@@ -25,37 +29,83 @@ func main() {
 	writer := api.NewIssueUpdateWriter(config)
 	defer writer.Close()
 
-	handler := api.NewIssueHandler(conn,config, writer)
+	resolvedWriter := api.NewIssueResolvedWriter(config)
+	defer resolvedWriter.Close()
+
+	regressionWriter := api.NewIssueRegressionWriter(config)
+	defer regressionWriter.Close()
+
+	handler := api.NewIssueHandler(conn,config, writer, resolvedWriter, regressionWriter)
 
 	err = conn.AutoMigrate(&models.Issue{})
     if err != nil {
         log.Fatalf("Failed to migrate issue table: %v", err)
     }
 
+	err = conn.AutoMigrate(&models.FingerprintRule{})
+	if err != nil {
+		log.Fatalf("Failed to migrate fingerprint rule table: %v", err)
+	}
+
+	err = conn.AutoMigrate(&models.EventRecord{}, &models.ProjectSettings{})
+	if err != nil {
+		log.Fatalf("Failed to migrate event tables: %v", err)
+	}
+
+	err = conn.AutoMigrate(&models.OutboxMessage{})
+	if err != nil {
+		log.Fatalf("Failed to migrate outbox table: %v", err)
+	}
+
+	err = conn.AutoMigrate(&dlq.ReplayRecord{})
+	if err != nil {
+		log.Fatalf("Failed to migrate dead-letter replay table: %v", err)
+	}
 
-	go kafka.Consume(handler)
+
+	consumer := kafka.NewConsumer(handler)
+	go consumer.Run()
+	go handler.StartRetention(time.Hour)
+	go handler.StartSnoozeExpiry(time.Minute)
+	go handler.StartOutboxRelay(time.Second)
 
 	
 
 	router := gin.Default()
 
-	router.Use(func(c *gin.Context) {
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-	c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	
-	if c.Request.Method == "OPTIONS" {
-		c.AbortWithStatus(204)
-		return
+	authMiddleware := auth.NewMiddleware(jwks.NewVerifier(config.TOKEN.JwksUrl), conn)
+
+	router.Use(auth.CORS(config.CORS.AllowedOrigins))
+	router.Use(authMiddleware.RequireAuth())
+
+	member := auth.RequireProjectRole(auth.RoleMember)
+	project := router.Group("/projects/:project_id", authMiddleware.RequireProjectAccess())
+	{
+		project.GET("/issues", handler.GetProjectIssue)
+		project.GET("/issues/:issue_id", handler.GetIssueDetail)
+		project.POST("/issues/:issue_id/resolve", member, handler.ResolveIssue)
+		project.POST("/issues/:issue_id/ignore", member, handler.IgnoreIssue)
+		project.POST("/issues/:issue_id/snooze", member, handler.SnoozeIssue)
+		project.POST("/issues/:issue_id/reopen", member, handler.ReopenIssue)
+		project.GET("/issues/:issue_id/events", handler.GetIssueEvents)
+		project.GET("/issues/:issue_id/events/latest", handler.GetLatestEvent)
+		project.GET("/issues/:issue_id/events/oldest", handler.GetOldestEvent)
+		project.GET("/issues/:issue_id/events/:event_id", handler.GetIssueEvent)
+		project.GET("/overview", handler.GetProjectOverview)
+		project.GET("/settings", handler.GetProjectSettings)
+		project.PUT("/settings", member, handler.UpdateProjectSettings)
+		project.POST("/fingerprint-rules", member, handler.CreateFingerprintRule)
+		project.GET("/fingerprint-rules", handler.GetFingerprintRules)
+		project.PUT("/fingerprint-rules/:rule_id", member, handler.UpdateFingerprintRule)
+		project.DELETE("/fingerprint-rules/:rule_id", member, handler.DeleteFingerprintRule)
+	}
+
+	admin := router.Group("/admin", auth.RequireAdmin(config.ADMIN.UserIDs))
+	{
+		admin.GET("/dlq", consumer.ListMessages)
+		admin.POST("/dlq/:offset/replay", consumer.ReplayMessage)
 	}
-	
-	c.Next()
-})
 
-	router.GET("/projects/:project_id/issues", handler.GetProjectIssue)
-	router.GET("/projects/:project_id/issues/:issue_id", handler.GetIssueDetail)
-	router.GET("/projects/:project_id/overview", handler.GetProjectOverview)
 	router.Run(":8082") 
 }
 
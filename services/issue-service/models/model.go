@@ -7,17 +7,29 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+	StatusIgnored  = "ignored"
+	StatusSnoozed  = "snoozed"
+	StatusRegressed = "regressed"
+)
+
 type Event struct {
 	ProjectID  string    `json:"project_id"`
 	Timestamp  time.Time `json:"timestamp"`
 	Level      string    `json:"level"`
 	Message    string    `json:"message"`
 	StackTrace string    `json:"stack_trace"`
+	Fingerprint []string `json:"fingerprint,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Release    string `json:"release,omitempty"`
 }
 
 type Issue struct {
 	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
 	Fingerprint string    `gorm:"uniqueIndex:idx_project_fp;not null" json:"fingerprint"`
+	FingerprintVersion int `gorm:"not null;default:1" json:"fingerprint_version"`
 	ProjectID   string    `gorm:"uniqueIndex:idx_project_fp;type:uuid;not null;index" json:"project_id"`
 	Title       string    `gorm:"not null" json:"title"`
 	Level       string    `gorm:"not null" json:"level"`
@@ -26,10 +38,61 @@ type Issue struct {
 	FirstSeen   time.Time `gorm:"autoCreateTime" json:"first_seen"`
 	LastSeen    time.Time `gorm:"autoUpdateTime" json:"last_seen"`
 	Status      string    `gorm:"default:'open'" json:"status"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+	SnoozeUntil *time.Time `json:"snooze_until"`
+	SnoozeUntilCount *int `json:"snooze_until_count"`
+	LastRegressedAt  *time.Time `json:"last_regressed_at"`
+	RegressedRelease string `json:"regressed_release,omitempty"`
+	RegressionCount  int `gorm:"default:0" json:"regression_count"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+type EventRecord struct {
+	ID         string            `gorm:"type:uuid;primaryKey" json:"id"`
+	IssueID    string            `gorm:"type:uuid;not null;index:idx_events_issue_ts" json:"issue_id"`
+	ProjectID  string            `gorm:"type:uuid;not null;index" json:"project_id"`
+	Level      string            `gorm:"not null" json:"level"`
+	Message    string            `gorm:"type:text;not null" json:"message"`
+	StackTrace string            `gorm:"type:text" json:"stack_trace"`
+	Tags       map[string]string `gorm:"type:text;serializer:json" json:"tags,omitempty"`
+	Release    string            `json:"release,omitempty"`
+	Timestamp  time.Time         `gorm:"not null;index:idx_events_issue_ts" json:"timestamp"`
+	ReceivedAt time.Time         `gorm:"autoCreateTime;index" json:"received_at"`
+}
+
+type ProjectSettings struct {
+	ProjectID          string    `gorm:"type:uuid;primaryKey" json:"project_id"`
+	EventRetentionDays int       `gorm:"not null" json:"event_retention_days"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type FingerprintRule struct {
+	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
+	ProjectID   string    `gorm:"type:uuid;not null;index" json:"project_id"`
+	Name        string    `gorm:"not null" json:"name"`
+	MatchField  string    `gorm:"not null" json:"match_field"`
+	MatchTag    string    `json:"match_tag,omitempty"`
+	Pattern     string    `gorm:"not null" json:"pattern"`
+	Fingerprint []string  `gorm:"type:text;serializer:json;not null" json:"fingerprint"`
+	Priority    int       `gorm:"default:0" json:"priority"`
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type OutboxMessage struct {
+	ID            string     `gorm:"type:uuid;primaryKey" json:"id"`
+	Topic         string     `gorm:"not null" json:"topic"`
+	Key           string     `gorm:"not null" json:"key"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_pending" json:"next_attempt_at"`
+	SentAt        *time.Time `gorm:"index:idx_outbox_pending" json:"sent_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
 
 func (i *Issue) BeforeCreate(tx *gorm.DB) error{
 	if i.ID == ""{
@@ -37,4 +100,32 @@ func (i *Issue) BeforeCreate(tx *gorm.DB) error{
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *FingerprintRule) BeforeCreate(tx *gorm.DB) error{
+	if r.ID == ""{
+		r.ID = uuid.New().String()
+	}
+
+	return nil
+}
+
+func (EventRecord) TableName() string {
+	return "events"
+}
+
+func (e *EventRecord) BeforeCreate(tx *gorm.DB) error{
+	if e.ID == ""{
+		e.ID = uuid.New().String()
+	}
+
+	return nil
+}
+
+func (m *OutboxMessage) BeforeCreate(tx *gorm.DB) error{
+	if m.ID == ""{
+		m.ID = uuid.New().String()
+	}
+
+	return nil
+}
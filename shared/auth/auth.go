@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
+	"gorm.io/gorm"
+)
+
+// Claims mirrors the token issued by identity-service.
+type Claims struct {
+	UserId    string `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type Middleware struct {
+	Verifier *jwks.Verifier
+	DB       *gorm.DB
+}
+
+func NewMiddleware(verifier *jwks.Verifier, db *gorm.DB) *Middleware {
+	return &Middleware{
+		Verifier: verifier,
+		DB:       db,
+	}
+}
+
+func (m *Middleware) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Missing or invalid token format"})
+			c.Abort()
+			return
+		}
+
+		claims := &Claims{}
+		token, err := m.Verifier.Parse(parts[1], claims)
+
+		if err != nil || !token.Valid || claims.UserId == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		active, err := SessionActive(m.DB, claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserId)
+		c.Set("email", claims.Email)
+
+		c.Next()
+	}
+}
+
+// SessionActive reports whether the session a token was issued for is still
+// live, so logout and refresh-token reuse take effect before the access token
+// expires. Tokens without a session can never be revoked and are rejected.
+func SessionActive(db *gorm.DB, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	var count int64
+	err := db.Table("sessions").Where("id = ? AND revoked_at IS NULL", sessionID).Count(&count).Error
+	return count > 0, err
+}
+
+const (
+	RoleViewer = "viewer"
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+)
+
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// RoleAtLeast reports whether role grants at least min. Unknown roles grant
+// nothing.
+func RoleAtLeast(role string, min string) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[min]
+}
+
+// RequireProjectAccess rejects requests for a :project_id outside the
+// caller's organizations and stores the caller's role as project_role. It
+// must run after RequireAuth.
+func (m *Middleware) RequireProjectAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.Authorize(c, c.Param("project_id")) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireProjectRole rejects callers whose project_role is below min. It must
+// run after RequireProjectAccess.
+func RequireProjectRole(min string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !RoleAtLeast(c.GetString("project_role"), min) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Authorize checks that the authenticated user can access projectID and
+// writes the error response when they cannot. Unknown and foreign projects
+// get the same 404 so project ids cannot be probed.
+func (m *Middleware) Authorize(c *gin.Context, projectID string) bool {
+	role, err := ProjectRole(m.DB, c.GetString("user_id"), projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify project access"})
+		return false
+	}
+
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return false
+	}
+
+	c.Set("project_role", role)
+	return true
+}
+
+// ProjectRole returns the user's role in the organization that owns
+// projectID, or "" when they are not a member.
+func ProjectRole(db *gorm.DB, userID string, projectID string) (string, error) {
+	if userID == "" || projectID == "" {
+		return "", nil
+	}
+
+	var roles []string
+	result := db.Table("projects").
+		Joins("JOIN memberships ON memberships.organization_id = projects.organization_id").
+		Where("projects.id = ? AND memberships.user_id = ?", projectID, userID).
+		Pluck("memberships.role", &roles)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	best := ""
+	for _, role := range roles {
+		if roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+
+	return best, nil
+}
+
+func CanAccessProject(db *gorm.DB, userID string, projectID string) (bool, error) {
+	role, err := ProjectRole(db, userID, projectID)
+	return role != "", err
+}
+
+// RequireAdmin limits operator routes to the configured user ids. It must run
+// after RequireAuth.
+func RequireAdmin(userIDs []string) gin.HandlerFunc {
+	admins := map[string]bool{}
+	for _, id := range userIDs {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			admins[id] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		if !admins[c.GetString("user_id")] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,209 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/k1ngalph0x/atlas/shared/auth"
+	"github.com/k1ngalph0x/atlas/shared/jwks"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testKey, otherKey = newKey(), newKey()
+
+type signingKey struct {
+	private ed25519.PrivateKey
+	jwk     jwks.Key
+}
+
+func newKey() signingKey {
+	pub, private, _ := ed25519.GenerateKey(rand.Reader)
+	jwk, _ := jwks.NewKey(pub)
+	return signingKey{private: private, jwk: jwk}
+}
+
+func jwksServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks.Set{Keys: []jwks.Key{testKey.jwk}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+
+	db.Exec("CREATE TABLE memberships (organization_id TEXT, user_id TEXT, role TEXT)")
+	db.Exec("CREATE TABLE projects (id TEXT PRIMARY KEY, organization_id TEXT)")
+	db.Exec("CREATE TABLE sessions (id TEXT PRIMARY KEY, user_id TEXT, revoked_at DATETIME)")
+	db.Exec("INSERT INTO memberships VALUES ('org-a', 'user-a', 'owner'), ('org-b', 'user-b', 'owner'), ('org-b', 'user-c', 'viewer'), ('org-b', 'user-d', 'member'), ('org-b', 'user-e', 'admin')")
+	db.Exec("INSERT INTO projects VALUES ('project-a', 'org-a'), ('project-b', 'org-b')")
+	db.Exec("INSERT INTO sessions (id, user_id) VALUES ('session-user-a', 'user-a'), ('session-user-b', 'user-b'), ('session-user-c', 'user-c'), ('session-user-d', 'user-d'), ('session-user-e', 'user-e')")
+	return db
+}
+
+// signToken signs a token for the live session setupTestDB creates for userID.
+func signToken(t *testing.T, userID string, key signingKey, expiresIn time.Duration) string {
+	t.Helper()
+	return signSessionToken(t, userID, "session-"+userID, key, expiresIn)
+}
+
+func signSessionToken(t *testing.T, userID string, sessionID string, key signingKey, expiresIn time.Duration) string {
+	t.Helper()
+	claims := &auth.Claims{
+		UserId:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = key.jwk.Kid
+	signed, err := token.SignedString(key.private)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func setupRouter(t *testing.T, db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	m := auth.NewMiddleware(jwks.NewVerifier(jwksServer(t)), db)
+
+	r := gin.New()
+	r.Use(m.RequireAuth())
+	r.GET("/projects/:project_id/issues", m.RequireProjectAccess(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id")})
+	})
+	r.GET("/projects/:project_id/manage", m.RequireProjectAccess(), auth.RequireProjectRole(auth.RoleMember), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": c.GetString("project_role")})
+	})
+	r.GET("/admin/dlq", auth.RequireAdmin([]string{"user-a"}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func get(r *gin.Engine, path string, token string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRequireAuth(t *testing.T) {
+	r := setupRouter(t, setupTestDB(t))
+
+	cases := map[string]string{
+		"missing":   "",
+		"wrong key": signToken(t, "user-a", otherKey, time.Hour),
+		"expired":   signToken(t, "user-a", testKey, -time.Hour),
+		"malformed": "not-a-jwt",
+	}
+	for name, token := range cases {
+		if code := get(r, "/projects/project-a/issues", token); code != http.StatusUnauthorized {
+			t.Errorf("%s token: expected 401, got %d", name, code)
+		}
+	}
+}
+
+func TestRequireAuth_RevokedSession(t *testing.T) {
+	db := setupTestDB(t)
+	db.Exec("INSERT INTO sessions (id, user_id) VALUES ('session-live', 'user-a')")
+	db.Exec("INSERT INTO sessions (id, user_id, revoked_at) VALUES ('session-revoked', 'user-a', CURRENT_TIMESTAMP)")
+	r := setupRouter(t, db)
+
+	if code := get(r, "/projects/project-a/issues", signSessionToken(t, "user-a", "session-live", testKey, time.Hour)); code != http.StatusOK {
+		t.Errorf("live session: expected 200, got %d", code)
+	}
+	if code := get(r, "/projects/project-a/issues", signSessionToken(t, "user-a", "session-revoked", testKey, time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("revoked session: expected 401, got %d", code)
+	}
+	if code := get(r, "/projects/project-a/issues", signSessionToken(t, "user-a", "session-unknown", testKey, time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("unknown session: expected 401, got %d", code)
+	}
+	if code := get(r, "/projects/project-a/issues", signSessionToken(t, "user-a", "", testKey, time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("no session: expected 401, got %d", code)
+	}
+}
+
+func TestRequireProjectAccess(t *testing.T) {
+	r := setupRouter(t, setupTestDB(t))
+	token := signToken(t, "user-a", testKey, time.Hour)
+
+	if code := get(r, "/projects/project-a/issues", token); code != http.StatusOK {
+		t.Errorf("own project: expected 200, got %d", code)
+	}
+	if code := get(r, "/projects/project-b/issues", token); code != http.StatusNotFound {
+		t.Errorf("other tenant's project: expected 404, got %d", code)
+	}
+	if code := get(r, "/projects/unknown/issues", token); code != http.StatusNotFound {
+		t.Errorf("unknown project: expected 404, got %d", code)
+	}
+
+	member := signToken(t, "user-c", testKey, time.Hour)
+	if code := get(r, "/projects/project-b/issues", member); code != http.StatusOK {
+		t.Errorf("invited member: expected 200, got %d", code)
+	}
+}
+
+func TestRequireProjectRole(t *testing.T) {
+	r := setupRouter(t, setupTestDB(t))
+
+	cases := map[string]int{
+		"user-b": http.StatusOK,
+		"user-e": http.StatusOK,
+		"user-d": http.StatusOK,
+		"user-c": http.StatusForbidden,
+		"user-a": http.StatusNotFound,
+	}
+	for userID, want := range cases {
+		token := signToken(t, userID, testKey, time.Hour)
+		if code := get(r, "/projects/project-b/manage", token); code != want {
+			t.Errorf("%s: expected %d, got %d", userID, want, code)
+		}
+		if code := get(r, "/projects/project-b/issues", token); want != http.StatusNotFound && code != http.StatusOK {
+			t.Errorf("%s: expected read access, got %d", userID, code)
+		}
+	}
+}
+
+func TestRoleAtLeast(t *testing.T) {
+	if !auth.RoleAtLeast(auth.RoleOwner, auth.RoleMember) || auth.RoleAtLeast(auth.RoleViewer, auth.RoleMember) {
+		t.Error("unexpected role ordering")
+	}
+	if auth.RoleAtLeast("", auth.RoleViewer) || auth.RoleAtLeast("superuser", auth.RoleViewer) {
+		t.Error("expected unknown roles to grant nothing")
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	r := setupRouter(t, setupTestDB(t))
+
+	if code := get(r, "/admin/dlq", signToken(t, "user-a", testKey, time.Hour)); code != http.StatusOK {
+		t.Errorf("admin: expected 200, got %d", code)
+	}
+	if code := get(r, "/admin/dlq", signToken(t, "user-b", testKey, time.Hour)); code != http.StatusForbidden {
+		t.Errorf("non-admin: expected 403, got %d", code)
+	}
+}
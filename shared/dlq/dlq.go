@@ -0,0 +1,357 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultMaxAttempts = 3
+
+	headerError           = "x-dlq-error"
+	headerSourceTopic     = "x-dlq-source-topic"
+	headerSourcePartition = "x-dlq-source-partition"
+	headerSourceOffset    = "x-dlq-source-offset"
+	headerConsumerGroup   = "x-dlq-consumer-group"
+	headerAttempts        = "x-dlq-attempts"
+	headerFailedAt        = "x-dlq-failed-at"
+)
+
+var (
+	ErrNotFound        = errors.New("message not found")
+	ErrReplayFailed    = errors.New("replay failed")
+	ErrAlreadyReplayed = errors.New("message already replayed")
+)
+
+var (
+	retryBackoff    = 500 * time.Millisecond
+	fetchBackoff    = time.Second
+	maxFetchBackoff = 30 * time.Second
+)
+
+// Consumer reads a topic as part of a consumer group, commits offsets only
+// after a message was handled, and moves messages that keep failing to a
+// dead-letter topic together with the error that caused it. Replays are
+// recorded in DB so a dead-lettered message is only replayed once.
+type Consumer struct {
+	Brokers         []string
+	Topic           string
+	GroupID         string
+	DeadLetterTopic string
+	MaxAttempts     int
+	Handle          func(kafka.Message) error
+	DB              *gorm.DB
+	writer          *kafka.Writer
+}
+
+// ReplayRecord marks a dead-lettered message as replayed.
+type ReplayRecord struct {
+	Topic      string    `gorm:"primaryKey" json:"topic"`
+	Offset     int64     `gorm:"primaryKey;autoIncrement:false" json:"offset"`
+	ReplayedAt time.Time `gorm:"autoCreateTime" json:"replayed_at"`
+}
+
+func (ReplayRecord) TableName() string {
+	return "dlq_replays"
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error as not worth retrying, e.g. a payload that cannot
+// be decoded. The message goes to the dead-letter topic straight away.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func NewConsumer(brokers []string, topic string, groupID string, deadLetterTopic string, db *gorm.DB, handle func(kafka.Message) error) *Consumer {
+	return &Consumer{
+		Brokers:         brokers,
+		Topic:           topic,
+		GroupID:         groupID,
+		DeadLetterTopic: deadLetterTopic,
+		MaxAttempts:     DefaultMaxAttempts,
+		Handle:          handle,
+		DB:              db,
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    deadLetterTopic,
+			Balancer: firstPartition{},
+		},
+	}
+}
+
+func (c *Consumer) Run() {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: c.Brokers,
+		Topic:   c.Topic,
+		GroupID: c.GroupID,
+	})
+	defer reader.Close()
+	defer c.writer.Close()
+
+	log.Printf("Kafka consumer %s started, listening on topic: %s", c.GroupID, c.Topic)
+
+	delay := fetchBackoff
+	for {
+		msg, err := reader.FetchMessage(context.Background())
+		if err != nil {
+			log.Printf("Kafka read error on %s, retrying in %s: %v", c.Topic, delay, err)
+			time.Sleep(delay)
+			delay = min(delay*2, maxFetchBackoff)
+			continue
+		}
+		delay = fetchBackoff
+
+		attempts, err := c.process(msg)
+		if err != nil {
+			c.deadLetter(msg, attempts, err)
+		}
+
+		for {
+			err = reader.CommitMessages(context.Background(), msg)
+			if err == nil {
+				break
+			}
+			log.Printf("Failed to commit offset %d on %s: %v", msg.Offset, msg.Topic, err)
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// process runs Handle until it succeeds, returns a permanent error or runs out
+// of attempts, and reports how many attempts were made.
+func (c *Consumer) process(msg kafka.Message) (int, error) {
+	maxAttempts := c.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = c.Handle(msg)
+		if err == nil {
+			return attempt, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return attempt, err
+		}
+
+		if attempt < maxAttempts {
+			log.Printf("Handler failed for %s offset %d (attempt %d): %v", msg.Topic, msg.Offset, attempt, err)
+			time.Sleep(retryBackoff << (attempt - 1))
+		}
+	}
+
+	return maxAttempts, err
+}
+
+// deadLetter keeps retrying until the message is on the dead-letter topic,
+// since committing the offset without it would lose the message.
+func (c *Consumer) deadLetter(msg kafka.Message, attempts int, cause error) {
+	log.Printf("Moving %s offset %d to %s after %d attempt(s): %v", msg.Topic, msg.Offset, c.DeadLetterTopic, attempts, cause)
+
+	dead := kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: []kafka.Header{
+			{Key: headerError, Value: []byte(cause.Error())},
+			{Key: headerSourceTopic, Value: []byte(msg.Topic)},
+			{Key: headerSourcePartition, Value: []byte(strconv.Itoa(msg.Partition))},
+			{Key: headerSourceOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+			{Key: headerConsumerGroup, Value: []byte(c.GroupID)},
+			{Key: headerAttempts, Value: []byte(strconv.Itoa(attempts))},
+			{Key: headerFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+		},
+	}
+
+	for {
+		err := c.writer.WriteMessages(context.Background(), dead)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to write to %s: %v", c.DeadLetterTopic, err)
+		time.Sleep(time.Second)
+	}
+}
+
+// firstPartition keeps the dead-letter topic on a single partition so it can
+// be listed and replayed by offset.
+type firstPartition struct{}
+
+func (firstPartition) Balance(msg kafka.Message, partitions ...int) int {
+	return partitions[0]
+}
+
+type Message struct {
+	Offset          int64      `json:"offset"`
+	Key             string     `json:"key"`
+	Payload         string     `json:"payload"`
+	Error           string     `json:"error"`
+	SourceTopic     string     `json:"source_topic"`
+	SourcePartition int        `json:"source_partition"`
+	SourceOffset    int64      `json:"source_offset"`
+	ConsumerGroup   string     `json:"consumer_group"`
+	Attempts        int        `json:"attempts"`
+	FailedAt        time.Time  `json:"failed_at"`
+	ReplayedAt      *time.Time `json:"replayed_at"`
+}
+
+func newMessage(m kafka.Message) Message {
+	headers := map[string]string{}
+	for _, h := range m.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	partition, _ := strconv.Atoi(headers[headerSourcePartition])
+	offset, _ := strconv.ParseInt(headers[headerSourceOffset], 10, 64)
+	attempts, _ := strconv.Atoi(headers[headerAttempts])
+	failedAt, _ := time.Parse(time.RFC3339, headers[headerFailedAt])
+
+	return Message{
+		Offset:          m.Offset,
+		Key:             string(m.Key),
+		Payload:         string(m.Value),
+		Error:           headers[headerError],
+		SourceTopic:     headers[headerSourceTopic],
+		SourcePartition: partition,
+		SourceOffset:    offset,
+		ConsumerGroup:   headers[headerConsumerGroup],
+		Attempts:        attempts,
+		FailedAt:        failedAt,
+	}
+}
+
+// List returns up to limit dead-lettered messages starting at offset.
+func (c *Consumer) List(offset int64, limit int) ([]kafka.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := kafka.DialLeader(ctx, "tcp", c.Brokers[0], c.DeadLetterTopic, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, err
+	}
+
+	if offset < first {
+		offset = first
+	}
+	if offset >= last || limit < 1 {
+		return nil, nil
+	}
+
+	_, err = conn.Seek(offset, kafka.SeekAbsolute)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	batch := conn.ReadBatch(1, 10e6)
+	defer batch.Close()
+
+	var messages []kafka.Message
+	for len(messages) < limit {
+		m, err := batch.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		messages = append(messages, m)
+		if m.Offset+1 >= last {
+			break
+		}
+	}
+
+	return messages, nil
+}
+
+// Replay hands a dead-lettered message back to this consumer's handler. It is
+// not republished to the source topic, since other consumer groups read it too.
+func (c *Consumer) Replay(offset int64) error {
+	messages, err := c.List(offset, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(messages) == 0 || messages[0].Offset != offset {
+		return ErrNotFound
+	}
+
+	return c.replay(messages[0])
+}
+
+// replay claims the offset before handling the message, so concurrent or
+// repeated replays of the same message return ErrAlreadyReplayed. A failed
+// replay releases the claim and can be tried again.
+func (c *Consumer) replay(dead kafka.Message) error {
+	claim := ReplayRecord{Topic: c.DeadLetterTopic, Offset: dead.Offset}
+	result := c.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&claim)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyReplayed
+	}
+
+	m := newMessage(dead)
+	_, err := c.process(kafka.Message{
+		Topic:     m.SourceTopic,
+		Partition: m.SourcePartition,
+		Offset:    m.SourceOffset,
+		Key:       dead.Key,
+		Value:     dead.Value,
+	})
+	if err != nil {
+		release := c.DB.Where("topic = ? AND \"offset\" = ?", claim.Topic, claim.Offset).Delete(&ReplayRecord{})
+		if release.Error != nil {
+			log.Printf("Failed to release replay of %s offset %d: %v", c.DeadLetterTopic, dead.Offset, release.Error)
+		}
+		return fmt.Errorf("%w: %v", ErrReplayFailed, err)
+	}
+
+	log.Printf("Replayed %s offset %d", c.DeadLetterTopic, dead.Offset)
+	return nil
+}
+
+// replayedAt returns when each of the given offsets was replayed.
+func (c *Consumer) replayedAt(offsets []int64) (map[int64]time.Time, error) {
+	replayed := map[int64]time.Time{}
+	if len(offsets) == 0 {
+		return replayed, nil
+	}
+
+	var records []ReplayRecord
+	result := c.DB.Where("topic = ? AND \"offset\" IN ?", c.DeadLetterTopic, offsets).Find(&records)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, r := range records {
+		replayed[r.Offset] = r.ReplayedAt
+	}
+	return replayed, nil
+}
@@ -0,0 +1,136 @@
+package dlq
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestProcess_RetriesUntilSuccess(t *testing.T) {
+	retryBackoff = time.Millisecond
+	calls := 0
+	c := &Consumer{MaxAttempts: 3, Handle: func(kafka.Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("db unavailable")
+		}
+		return nil
+	}}
+
+	attempts, err := c.process(kafka.Message{})
+	if err != nil || attempts != 3 {
+		t.Errorf("expected success on attempt 3, got attempts=%d err=%v", attempts, err)
+	}
+}
+
+func TestProcess_GivesUpAfterMaxAttempts(t *testing.T) {
+	retryBackoff = time.Millisecond
+	calls := 0
+	c := &Consumer{MaxAttempts: 3, Handle: func(kafka.Message) error {
+		calls++
+		return errors.New("db unavailable")
+	}}
+
+	attempts, err := c.process(kafka.Message{})
+	if err == nil || attempts != 3 || calls != 3 {
+		t.Errorf("expected 3 failed attempts, got attempts=%d calls=%d err=%v", attempts, calls, err)
+	}
+}
+
+func TestProcess_PermanentErrorIsNotRetried(t *testing.T) {
+	calls := 0
+	c := &Consumer{MaxAttempts: 3, Handle: func(kafka.Message) error {
+		calls++
+		return Permanent(errors.New("invalid event"))
+	}}
+
+	attempts, err := c.process(kafka.Message{})
+	if err == nil || attempts != 1 || calls != 1 {
+		t.Errorf("expected a single attempt, got attempts=%d calls=%d err=%v", attempts, calls, err)
+	}
+}
+
+func TestNewMessage_ReadsHeaders(t *testing.T) {
+	m := newMessage(kafka.Message{
+		Offset: 7,
+		Key:    []byte("project"),
+		Value:  []byte("{bad json"),
+		Headers: []kafka.Header{
+			{Key: headerError, Value: []byte("invalid event")},
+			{Key: headerSourceTopic, Value: []byte("atlas-events")},
+			{Key: headerSourceOffset, Value: []byte("42")},
+			{Key: headerAttempts, Value: []byte("1")},
+		},
+	})
+
+	if m.Offset != 7 || m.Payload != "{bad json" || m.Error != "invalid event" || m.SourceTopic != "atlas-events" || m.SourceOffset != 42 || m.Attempts != 1 {
+		t.Errorf("unexpected message: %+v", m)
+	}
+}
+
+func setupReplayDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dlq.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&ReplayRecord{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestReplay_OnlyOnce(t *testing.T) {
+	calls := 0
+	c := &Consumer{DeadLetterTopic: "atlas-events.dlq", MaxAttempts: 1, DB: setupReplayDB(t), Handle: func(kafka.Message) error {
+		calls++
+		return nil
+	}}
+	dead := kafka.Message{Offset: 3, Value: []byte("{}")}
+
+	if err := c.replay(dead); err != nil {
+		t.Fatalf("expected the first replay to succeed, got %v", err)
+	}
+	if err := c.replay(dead); !errors.Is(err, ErrAlreadyReplayed) {
+		t.Fatalf("expected ErrAlreadyReplayed, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the handler to run once, got %d", calls)
+	}
+
+	replayed, err := c.replayedAt([]int64{3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := replayed[3]; !ok || len(replayed) != 1 {
+		t.Errorf("expected only offset 3 to be marked replayed, got %v", replayed)
+	}
+}
+
+func TestReplay_FailureCanBeRetried(t *testing.T) {
+	fail := true
+	c := &Consumer{DeadLetterTopic: "atlas-events.dlq", MaxAttempts: 1, DB: setupReplayDB(t), Handle: func(kafka.Message) error {
+		if fail {
+			return errors.New("db unavailable")
+		}
+		return nil
+	}}
+	dead := kafka.Message{Offset: 5, Value: []byte("{}")}
+
+	if err := c.replay(dead); !errors.Is(err, ErrReplayFailed) {
+		t.Fatalf("expected ErrReplayFailed, got %v", err)
+	}
+
+	fail = false
+	if err := c.replay(dead); err != nil {
+		t.Fatalf("expected a failed replay to be retryable, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package dlq
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 100
+)
+
+func (c *Consumer) ListMessages(ctx *gin.Context) {
+	offset, err := strconv.ParseInt(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil || offset < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultListLimit)))
+	if err != nil || limit < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	raw, err := c.List(offset, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read dead-letter queue"})
+		return
+	}
+
+	offsets := make([]int64, 0, len(raw))
+	for _, m := range raw {
+		offsets = append(offsets, m.Offset)
+	}
+
+	replayed, err := c.replayedAt(offsets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read replay history"})
+		return
+	}
+
+	next := offset
+	messages := make([]Message, 0, len(raw))
+	for _, m := range raw {
+		message := newMessage(m)
+		if at, ok := replayed[m.Offset]; ok {
+			message.ReplayedAt = &at
+		}
+		messages = append(messages, message)
+		next = m.Offset + 1
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"topic":       c.DeadLetterTopic,
+		"messages":    messages,
+		"next_offset": next,
+	})
+}
+
+func (c *Consumer) ReplayMessage(ctx *gin.Context) {
+	offset, err := strconv.ParseInt(ctx.Param("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	err = c.Replay(offset)
+	if errors.Is(err, ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return
+	}
+	if errors.Is(err, ErrAlreadyReplayed) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Message already replayed"})
+		return
+	}
+	if errors.Is(err, ErrReplayFailed) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read dead-letter queue"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "replayed", "offset": offset})
+}
@@ -0,0 +1,144 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultCacheTTL = 10 * time.Minute
+
+	// minRefreshInterval stops tokens with unknown kids from turning into a
+	// request to identity-service each.
+	minRefreshInterval = 30 * time.Second
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Verifier checks tokens against the JWKS published by identity-service. Keys
+// are cached for TTL and refetched early when a token names an unknown kid,
+// which is how rotated keys are picked up.
+type Verifier struct {
+	URL    string
+	TTL    time.Duration
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func NewVerifier(url string) *Verifier {
+	return &Verifier{
+		URL:    url,
+		TTL:    DefaultCacheTTL,
+		client: &http.Client{Timeout: 5 * time.Second},
+		keys:   map[string]crypto.PublicKey{},
+	}
+}
+
+func (v *Verifier) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, v.Keyfunc, jwt.WithValidMethods([]string{AlgEdDSA, AlgRS256}))
+}
+
+func (v *Verifier) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("token has no kid")
+	}
+
+	key, err := v.key(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, MatchAlg(token.Method.Alg(), key)
+}
+
+func (v *Verifier) key(kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	fresh := !v.fetchedAt.IsZero() && time.Since(v.fetchedAt) < v.TTL
+	if ok && fresh {
+		return key, nil
+	}
+
+	if time.Since(v.lastAttempt) >= minRefreshInterval {
+		err := v.refresh()
+		if err != nil {
+			log.Printf("Failed to refresh JWKS from %s: %v", v.URL, err)
+		}
+	}
+
+	key, ok = v.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// refresh must be called with mu held.
+func (v *Verifier) refresh() error {
+	v.lastAttempt = time.Now()
+
+	resp, err := v.client.Get(v.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var set Set
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		pub, err := k.PublicKey()
+		if err != nil {
+			log.Printf("Skipping JWKS key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+// MatchAlg rejects tokens whose alg header does not fit the key type, so an
+// RSA key can never be used to check an EdDSA signature or the reverse.
+func MatchAlg(alg string, key crypto.PublicKey) error {
+	switch key.(type) {
+	case ed25519.PublicKey:
+		if alg == AlgEdDSA {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if alg == AlgRS256 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("algorithm %s does not match key", alg)
+}
@@ -0,0 +1,148 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testServer struct {
+	mu      sync.Mutex
+	keys    []Key
+	fetches int
+	url     string
+}
+
+func newTestServer(t *testing.T, keys ...Key) *testServer {
+	t.Helper()
+	s := &testServer{keys: keys}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+		json.NewEncoder(w).Encode(Set{Keys: s.keys})
+	}))
+	t.Cleanup(srv.Close)
+	s.url = srv.URL
+	return s
+}
+
+func (s *testServer) setKeys(keys ...Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func newEd25519(t *testing.T) (ed25519.PrivateKey, Key) {
+	t.Helper()
+	pub, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	key, err := NewKey(pub)
+	if err != nil {
+		t.Fatalf("failed to build jwk: %v", err)
+	}
+	return private, key
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, private interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(private)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return signed
+}
+
+func TestVerifier_CachesKeys(t *testing.T) {
+	private, key := newEd25519(t)
+	srv := newTestServer(t, key)
+	v := NewVerifier(srv.url)
+
+	token := sign(t, jwt.SigningMethodEdDSA, key.Kid, private)
+	for i := 0; i < 5; i++ {
+		if _, err := v.Parse(token, &jwt.RegisteredClaims{}); err != nil {
+			t.Fatalf("expected valid token, got %v", err)
+		}
+	}
+
+	if srv.fetches != 1 {
+		t.Errorf("expected JWKS to be fetched once, got %d", srv.fetches)
+	}
+}
+
+func TestVerifier_PicksUpRotatedKey(t *testing.T) {
+	oldPrivate, oldKey := newEd25519(t)
+	newPrivate, newKey := newEd25519(t)
+	srv := newTestServer(t, oldKey)
+	v := NewVerifier(srv.url)
+
+	if _, err := v.Parse(sign(t, jwt.SigningMethodEdDSA, oldKey.Kid, oldPrivate), &jwt.RegisteredClaims{}); err != nil {
+		t.Fatalf("expected old key to verify, got %v", err)
+	}
+
+	srv.setKeys(newKey, oldKey)
+	v.lastAttempt = time.Time{}
+
+	if _, err := v.Parse(sign(t, jwt.SigningMethodEdDSA, newKey.Kid, newPrivate), &jwt.RegisteredClaims{}); err != nil {
+		t.Fatalf("expected rotated key to verify, got %v", err)
+	}
+	if srv.fetches != 2 {
+		t.Errorf("expected an unknown kid to trigger one refetch, got %d fetches", srv.fetches)
+	}
+
+	if _, err := v.Parse(sign(t, jwt.SigningMethodEdDSA, "missing", newPrivate), &jwt.RegisteredClaims{}); err == nil {
+		t.Error("expected unknown kid to be rejected")
+	}
+	if srv.fetches != 2 {
+		t.Errorf("expected unknown kids not to refetch within the refresh interval, got %d fetches", srv.fetches)
+	}
+}
+
+func TestVerifier_RejectsHMACAndMismatchedAlg(t *testing.T) {
+	_, key := newEd25519(t)
+	v := NewVerifier(newTestServer(t, key).url)
+
+	if _, err := v.Parse(sign(t, jwt.SigningMethodHS256, key.Kid, []byte("secret")), &jwt.RegisteredClaims{}); err == nil {
+		t.Error("expected HS256 token to be rejected")
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	if _, err := v.Parse(sign(t, jwt.SigningMethodRS256, key.Kid, rsaKey), &jwt.RegisteredClaims{}); err == nil {
+		t.Error("expected RS256 token to be rejected for an Ed25519 kid")
+	}
+}
+
+func TestKey_RSARoundTrip(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+
+	key, err := NewKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to build jwk: %v", err)
+	}
+	if key.Alg != AlgRS256 || key.Kid == "" {
+		t.Fatalf("unexpected jwk: %+v", key)
+	}
+
+	v := NewVerifier(newTestServer(t, key).url)
+	if _, err := v.Parse(sign(t, jwt.SigningMethodRS256, key.Kid, private), &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("expected RS256 token to verify, got %v", err)
+	}
+}
@@ -15,6 +15,8 @@ type Project struct {
 	ProjectName    string    `gorm:"not null" json:"project_name"`
 	OrganizationID string    `gorm:"type:uuid;not null;index" json:"organization_id"`
 	//APIKey         string    `gorm:"type:varchar(128);unique;not null;index" json:"api_key"`
+	// APIKey holds the hash of the key issued at creation. Ingestion reads
+	// api_keys instead, where that key is copied as "Default".
 	APIKey         string    `gorm:"type:varchar(128);unique;not null;index" json:"-"`
 	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
@@ -30,6 +32,89 @@ func (p *Project) BeforeCreate(tx *gorm.DB) error{
 	return nil
 }
 
+// APIKey is one of a project's ingestion keys. Only the hash is stored;
+// Prefix is kept so a key can be recognised in listings.
+type APIKey struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	ProjectID  string     `gorm:"type:uuid;not null;index" json:"project_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16)" json:"prefix"`
+	KeyHash    string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Active reports whether the key may still authenticate ingestion requests.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+func NewAPIKey(projectID, name, createdBy string) (*APIKey, string) {
+	key := generateAPIKey()
+	return &APIKey{
+		ProjectID: projectID,
+		Name:      name,
+		Prefix:    APIKeyPrefix(key),
+		KeyHash:   HashAPIKey(key),
+		CreatedBy: createdBy,
+	}, key
+}
+
+// ProjectLimit overrides ingestion-service's default limits for one project.
+// Nil fields fall back to the defaults.
+type ProjectLimit struct {
+	ProjectID          string    `gorm:"type:uuid;primaryKey" json:"project_id"`
+	EventsPerSecond    *float64  `json:"events_per_second"`
+	Burst              *int      `json:"burst"`
+	KeyEventsPerSecond *float64  `json:"key_events_per_second"`
+	KeyBurst           *int      `json:"key_burst"`
+	MonthlyQuota       *int64    `json:"monthly_quota"`
+	SpikeProtection    *bool     `json:"spike_protection"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ProjectUsage counts a project's ingested and dropped events per calendar
+// month (UTC, "2006-01").
+type ProjectUsage struct {
+	ProjectID     string    `gorm:"type:uuid;primaryKey" json:"project_id"`
+	Month         string    `gorm:"type:varchar(7);primaryKey" json:"month"`
+	Accepted      int64     `gorm:"not null;default:0" json:"accepted"`
+	RateLimited   int64     `gorm:"not null;default:0" json:"rate_limited"`
+	QuotaExceeded int64     `gorm:"not null;default:0" json:"quota_exceeded"`
+	SpikeDropped  int64     `gorm:"not null;default:0" json:"spike_dropped"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// APIKeyEventsTopic carries APIKeyEvents from identity-service so ingestion
+// instances can drop cached keys.
+const APIKeyEventsTopic = "api-key-events"
+
+const (
+	APIKeyRevoked = "revoked"
+	APIKeyRotated = "rotated"
+)
+
+type APIKeyEvent struct {
+	KeyID      string    `json:"key_id"`
+	ProjectID  string    `json:"project_id"`
+	KeyHash    string    `json:"key_hash"`
+	Action     string    `json:"action"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
 type ProjectWithRawKey struct {
 	Project
 	RawAPIKey string `json:"api_key"`
@@ -45,12 +130,22 @@ func NewProject(name, orgID string)(*Project, string){
 }
 
 
+// apiKeyPrefixLen covers "atlas_" and the first 8 hex characters.
+const apiKeyPrefixLen = 14
+
 func generateAPIKey() string{
 	randomBytes := make([]byte, 32)
 	rand.Read(randomBytes)
 	return "atlas_" + hex.EncodeToString(randomBytes)
 }
 
+func APIKeyPrefix(raw string) string {
+	if len(raw) < apiKeyPrefixLen {
+		return raw
+	}
+	return raw[:apiKeyPrefixLen]
+}
+
 func HashAPIKey(raw string) string {
 	sum := sha256.Sum256([]byte(raw))
 	return hex.EncodeToString(sum[:])
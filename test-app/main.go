@@ -10,6 +10,7 @@ import (
 
 func main() {
 	client := atlas.NewClient("atlas_96b846403c49fcaca9cc7dfca209f574bdbad503fce174cda80f2393f7337055")
+	defer client.Close()
 
 	router := gin.Default()
 